@@ -0,0 +1,590 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// generator accumulates the Go type/function definitions produced while
+// walking a document's operations against a schema, and renders them into
+// a single source file.
+type generator struct {
+	sc          *schema
+	doc         *document
+	nullability string // "pointer" (default) or "optional"
+
+	order      []string          // definition names, in first-emitted order
+	defs       map[string]string // definition name -> Go source
+	seen       map[string]bool
+	ifaceTypes map[string]bool // Go type names that are generated interfaces
+}
+
+func newGenerator(sc *schema, doc *document, nullability string) *generator {
+	if nullability == "" {
+		nullability = "pointer"
+	}
+	return &generator{
+		sc:          sc,
+		doc:         doc,
+		nullability: nullability,
+		defs:        map[string]string{},
+		seen:        map[string]bool{},
+		ifaceTypes:  map[string]bool{},
+	}
+}
+
+func (g *generator) define(name, src string) {
+	if g.seen[name] {
+		return
+	}
+	g.seen[name] = true
+	g.order = append(g.order, name)
+	g.defs[name] = src
+}
+
+// generate renders the complete generated Go source for doc against sc,
+// as package packageName.
+func generate(sc *schema, doc *document, packageName, nullability string) (string, error) {
+	g := newGenerator(sc, doc, nullability)
+
+	var wrappers []string
+	for _, op := range doc.operations {
+		if op.name == "" {
+			return "", fmt.Errorf("anonymous operations are not supported by graphqlgen; give it a name")
+		}
+		varsType := op.name + "Variables"
+		respType := op.name + "Response"
+
+		if err := g.genVariablesStruct(varsType, op.vars); err != nil {
+			return "", fmt.Errorf("operation %s: %w", op.name, err)
+		}
+
+		rootType, err := g.operationRootType(op.kind)
+		if err != nil {
+			return "", fmt.Errorf("operation %s: %w", op.name, err)
+		}
+		if _, err := g.genSelectionStruct(respType, rootType, op.selections); err != nil {
+			return "", fmt.Errorf("operation %s: %w", op.name, err)
+		}
+
+		wrapper, err := g.genWrapperFunc(op, varsType, respType)
+		if err != nil {
+			return "", fmt.Errorf("operation %s: %w", op.name, err)
+		}
+		wrappers = append(wrappers, wrapper)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by graphqlgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", packageName)
+	b.WriteString("import (\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\n\t\"github.com/machinebox/graphql\"\n)\n\n")
+	for _, name := range g.order {
+		b.WriteString(g.defs[name])
+		b.WriteString("\n")
+	}
+	for _, w := range wrappers {
+		b.WriteString(w)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+func (g *generator) operationRootType(kind string) (string, error) {
+	switch kind {
+	case "query":
+		return g.sc.queryType, nil
+	case "mutation":
+		return g.sc.mutType, nil
+	case "subscription":
+		return g.sc.subType, nil
+	default:
+		return "", fmt.Errorf("unknown operation kind %q", kind)
+	}
+}
+
+// genVariablesStruct emits the <Op>Variables struct for an operation's
+// variable definitions.
+func (g *generator) genVariablesStruct(name string, vars []variableDef) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s holds the variables for this operation.\ntype %s struct {\n", name, name)
+	for _, v := range vars {
+		goType, err := g.typeRefGoType(v.typ, true)
+		if err != nil {
+			return fmt.Errorf("variable $%s: %w", v.name, err)
+		}
+		fmt.Fprintf(&b, "\t%s %s %s\n", goFieldName(v.name), goType, jsonFieldTag(v.name, v.typ))
+	}
+	b.WriteString("}\n")
+	g.define(name, b.String())
+	return nil
+}
+
+// jsonFieldTag builds the json struct tag for a field whose nullability
+// is governed by t: required fields are never marshaled as omitted
+// (a zero value, e.g. 0 or false, is a real value GraphQL expects to
+// receive, not an absent variable), optional fields use omitempty.
+func jsonFieldTag(key string, t *typeRef) string {
+	if t != nil && t.nonNull {
+		return fmt.Sprintf("`json:\"%s\"`", key)
+	}
+	return fmt.Sprintf("`json:\"%s,omitempty\"`", key)
+}
+
+// baseTypeRef unwraps list wrappers to the named leaf type.
+func baseTypeRef(t *typeRef) *typeRef {
+	for t.listOf != nil {
+		t = t.listOf
+	}
+	return t
+}
+
+// wrapByTypeRef re-applies t's list/nullability shape around a resolved
+// leaf Go type. Null lists are represented by a nil slice, so list
+// nullability needs no wrapper; scalar/enum/input/object nullability is
+// represented with a pointer (or Optional[T], with -nullability=optional).
+func (g *generator) wrapByTypeRef(t *typeRef, leaf string) string {
+	if t.listOf != nil {
+		return "[]" + g.wrapByTypeRef(t.listOf, leaf)
+	}
+	if !t.nonNull {
+		return g.wrapNullable(leaf)
+	}
+	return leaf
+}
+
+func (g *generator) wrapNullable(goType string) string {
+	if g.nullability == "optional" {
+		return fmt.Sprintf("graphql.Optional[%s]", goType)
+	}
+	return "*" + goType
+}
+
+// typeRefGoType resolves the Go type for a schema type reference. Used
+// for variables and input object fields (asInput), where the whole type
+// is schema-driven rather than shaped by a selection set.
+func (g *generator) typeRefGoType(t *typeRef, asInput bool) (string, error) {
+	base := baseTypeRef(t)
+	leaf, err := g.leafGoType(base.name, asInput)
+	if err != nil {
+		return "", err
+	}
+	return g.wrapByTypeRef(t, leaf), nil
+}
+
+func (g *generator) leafGoType(name string, asInput bool) (string, error) {
+	switch name {
+	case "String", "ID":
+		return "string", nil
+	case "Int":
+		return "int", nil
+	case "Float":
+		return "float64", nil
+	case "Boolean":
+		return "bool", nil
+	}
+	if g.sc.isEnum(name) {
+		g.defineEnum(name)
+		return name, nil
+	}
+	if g.sc.isScalar(name) {
+		// a custom scalar with no declared Go mapping decodes as its raw
+		// JSON string representation.
+		return "string", nil
+	}
+	if asInput {
+		if _, ok := g.sc.inputs[name]; ok {
+			if err := g.defineInput(name); err != nil {
+				return "", err
+			}
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("type %q cannot appear here", name)
+}
+
+func (g *generator) defineEnum(name string) {
+	if g.seen[name] {
+		return
+	}
+	def := g.sc.enums[name]
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s is a GraphQL enum.\ntype %s string\n\nconst (\n", name, name)
+	for _, v := range def.values {
+		fmt.Fprintf(&b, "\t%s%s %s = %q\n", name, v, name, v)
+	}
+	b.WriteString(")\n")
+	g.define(name, b.String())
+}
+
+func (g *generator) defineInput(name string) error {
+	if g.seen[name] {
+		return nil
+	}
+	g.seen[name] = true // mark before recursing, to tolerate self-referential inputs
+	def, ok := g.sc.inputs[name]
+	if !ok {
+		return fmt.Errorf("unknown input type %q", name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s is a GraphQL input type.\ntype %s struct {\n", name, name)
+	for _, f := range def.fields {
+		goType, err := g.typeRefGoType(f.typ, true)
+		if err != nil {
+			return fmt.Errorf("input %s.%s: %w", name, f.name, err)
+		}
+		fmt.Fprintf(&b, "\t%s %s %s\n", goFieldName(f.name), goType, jsonFieldTag(f.name, f.typ))
+	}
+	b.WriteString("}\n")
+	g.order = append(g.order, name)
+	g.defs[name] = b.String()
+	return nil
+}
+
+// responseField is one resolved field of a generated response struct.
+type responseField struct {
+	goName  string
+	goType  string
+	jsonKey string
+	isIface bool   // true if this field's (unwrapped) type is a generated interface
+	isList  bool   // true if isIface and the field is a list of it
+	iface   string // the interface's Go type name, when isIface
+}
+
+// genSelectionStruct emits the Go type selected by sels against
+// parentType (an object, interface or union), returning its Go type
+// name: either a struct (objects, or abstract types with no explicit
+// variant selected) or an interface (abstract types with >=1 inline
+// fragment naming a concrete member).
+func (g *generator) genSelectionStruct(name, parentType string, sels []selection) (string, error) {
+	flat, err := g.expandFragments(sels, parentType)
+	if err != nil {
+		return "", err
+	}
+
+	if g.sc.isInterface(parentType) || g.sc.isUnion(parentType) {
+		common, variants := splitVariants(flat)
+		if len(variants) == 0 {
+			// no variant selected explicit concrete type: fall back to a
+			// plain struct of the common fields only.
+			return name, g.genStruct(name, parentType, common)
+		}
+		return g.genAbstractType(name, parentType, common, variants)
+	}
+	return name, g.genStruct(name, parentType, flat)
+}
+
+// expandFragments resolves named fragment spreads and merges anonymous
+// inline fragments (no type condition) into the current level, since
+// both apply unconditionally to parentType. Named-type inline fragments
+// (including those reached through a fragment spread) are left as-is for
+// the caller to dispatch on.
+func (g *generator) expandFragments(sels []selection, parentType string) ([]selection, error) {
+	var out []selection
+	for _, sel := range sels {
+		switch {
+		case sel.fragmentSpread != "":
+			frag, ok := g.doc.fragments[sel.fragmentSpread]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %q", sel.fragmentSpread)
+			}
+			if frag.on != "" && frag.on != parentType && !g.sc.isInterface(frag.on) && !g.sc.isUnion(frag.on) {
+				out = append(out, selection{onType: frag.on, selections: frag.selections})
+				continue
+			}
+			inner, err := g.expandFragments(frag.selections, parentType)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, inner...)
+		case sel.name == "" && sel.onType == "":
+			// anonymous inline fragment
+			inner, err := g.expandFragments(sel.selections, parentType)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, inner...)
+		default:
+			out = append(out, sel)
+		}
+	}
+	return out, nil
+}
+
+// splitVariants separates plain field selections (common to every
+// variant of an abstract type) from inline fragments naming a concrete
+// member type.
+func splitVariants(sels []selection) (common []selection, variants map[string][]selection) {
+	variants = map[string][]selection{}
+	for _, sel := range sels {
+		if sel.onType != "" {
+			variants[sel.onType] = append(variants[sel.onType], sel.selections...)
+			continue
+		}
+		if sel.name != "__typename" {
+			common = append(common, sel)
+		}
+	}
+	return common, variants
+}
+
+// genAbstractType emits an interface named `name` plus one struct per
+// variant (name+variantType), each carrying the common fields, the
+// variant's own fields, and a __typename discriminator field. It also
+// registers the unmarshal-by-__typename helper used by whichever struct
+// ends up with a field of this interface type.
+func (g *generator) genAbstractType(name, parentType string, common []selection, variants map[string][]selection) (string, error) {
+	var variantNames []string
+	for v := range variants {
+		variantNames = append(variantNames, v)
+	}
+	sort.Strings(variantNames)
+
+	var iface strings.Builder
+	fmt.Fprintf(&iface, "// %s is implemented by every concrete type selected for this %s field.\ntype %s interface {\n\tis%s()\n}\n", name, parentType, name, name)
+	g.define(name, iface.String())
+	g.ifaceTypes[name] = true
+
+	var switchCases strings.Builder
+	for _, v := range variantNames {
+		variantName := name + v
+		fields := append(append([]selection{{name: "__typename", alias: "__typename"}}, common...), variants[v]...)
+		if err := g.genStruct(variantName, v, fields); err != nil {
+			return "", err
+		}
+		g.define(variantName+"_marker", fmt.Sprintf("func (v %s) is%s() {}\n", variantName, name))
+		switchCases.WriteString(fmt.Sprintf("\tcase %q:\n\t\tvar v %s\n\t\tif err := json.Unmarshal(b, &v); err != nil {\n\t\t\treturn nil, err\n\t\t}\n\t\treturn v, nil\n", v, variantName))
+	}
+
+	var helper strings.Builder
+	fmt.Fprintf(&helper, "func unmarshal%s(b []byte) (%s, error) {\n\tvar disc struct {\n\t\tTypename string `json:\"__typename\"`\n\t}\n\tif err := json.Unmarshal(b, &disc); err != nil {\n\t\treturn nil, err\n\t}\n\tswitch disc.Typename {\n%s\tdefault:\n\t\treturn nil, fmt.Errorf(\"%s: unknown __typename %%q\", disc.Typename)\n\t}\n}\n", name, name, switchCases.String(), name)
+	g.define(name+"_unmarshal", helper.String())
+
+	return name, nil
+}
+
+// genStruct emits a plain Go struct for an object type, resolving each
+// selected field's Go type from parentType's schema fields (recursing
+// into genSelectionStruct for object/interface/union fields).
+func (g *generator) genStruct(name, parentType string, sels []selection) error {
+	fields, err := g.resolveFields(name, parentType, sels)
+	if err != nil {
+		return err
+	}
+
+	hasIface := false
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s was selected from %s.\ntype %s struct {\n", name, parentType, name)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\t%s %s `json:\"%s,omitempty\"`\n", f.goName, f.goType, f.jsonKey)
+		hasIface = hasIface || f.isIface
+	}
+	b.WriteString("}\n")
+	g.define(name, b.String())
+
+	if hasIface {
+		g.define(name+"_unmarshal", g.genStructUnmarshal(name, fields))
+	}
+	return nil
+}
+
+// resolveFields merges duplicate-alias selections (the same field
+// selected twice, e.g. once directly and once via a fragment) and
+// resolves each one's Go type against parentType's schema field.
+func (g *generator) resolveFields(structName, parentType string, sels []selection) ([]responseField, error) {
+	order := []string{}
+	merged := map[string]selection{}
+	for _, sel := range sels {
+		if existing, ok := merged[sel.alias]; ok {
+			existing.selections = append(existing.selections, sel.selections...)
+			merged[sel.alias] = existing
+			continue
+		}
+		merged[sel.alias] = sel
+		order = append(order, sel.alias)
+	}
+
+	var fields []responseField
+	for _, alias := range order {
+		sel := merged[alias]
+		if sel.name == "__typename" {
+			fields = append(fields, responseField{goName: goFieldName(alias), goType: "string", jsonKey: alias})
+			continue
+		}
+		fd := findField(g.sc, parentType, sel.name)
+		if fd == nil {
+			return nil, fmt.Errorf("%s: unknown field %q on type %s", structName, sel.name, parentType)
+		}
+		rf, err := g.resolveField(structName, alias, fd, sel)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, rf)
+	}
+	return fields, nil
+}
+
+func findField(sc *schema, typeName, fieldName string) *fieldDef {
+	for _, f := range sc.fieldsOf(typeName) {
+		if f.name == fieldName {
+			return &f
+		}
+	}
+	return nil
+}
+
+func (g *generator) resolveField(structName, alias string, fd *fieldDef, sel selection) (responseField, error) {
+	base := baseTypeRef(fd.typ)
+	goName := goFieldName(alias)
+
+	if g.sc.isScalar(base.name) || g.sc.isEnum(base.name) {
+		leaf, err := g.leafGoType(base.name, false)
+		if err != nil {
+			return responseField{}, err
+		}
+		return responseField{goName: goName, goType: g.wrapByTypeRef(fd.typ, leaf), jsonKey: alias}, nil
+	}
+
+	if len(sel.selections) == 0 {
+		return responseField{}, fmt.Errorf("%s: field %q of type %s must have a selection set", structName, sel.name, base.name)
+	}
+	nestedName := structName + goFieldName(alias)
+	innerType, err := g.genSelectionStruct(nestedName, base.name, sel.selections)
+	if err != nil {
+		return responseField{}, err
+	}
+	goType := g.wrapByTypeRef(fd.typ, innerType)
+	rf := responseField{goName: goName, goType: goType, jsonKey: alias}
+	if g.ifaceTypes[innerType] {
+		rf.isIface = true
+		rf.iface = innerType
+		rf.isList = fd.typ.listOf != nil
+	}
+	return rf, nil
+}
+
+// genStructUnmarshal emits a custom UnmarshalJSON for a struct that has
+// one or more interface-typed fields, decoding those via the matching
+// unmarshal<Interface> helper and copying every other field straight
+// through.
+func (g *generator) genStructUnmarshal(name string, fields []responseField) string {
+	var aux strings.Builder
+	aux.WriteString("type " + name + "__raw struct {\n")
+	for _, f := range fields {
+		if f.isIface {
+			rawType := "json.RawMessage"
+			if f.isList {
+				rawType = "[]json.RawMessage"
+			}
+			fmt.Fprintf(&aux, "\t%s %s `json:\"%s,omitempty\"`\n", f.goName, rawType, f.jsonKey)
+			continue
+		}
+		fmt.Fprintf(&aux, "\t%s %s `json:\"%s,omitempty\"`\n", f.goName, f.goType, f.jsonKey)
+	}
+	aux.WriteString("}\n\n")
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "func (v *%s) UnmarshalJSON(b []byte) error {\n\tvar raw %s__raw\n\tif err := json.Unmarshal(b, &raw); err != nil {\n\t\treturn err\n\t}\n", name, name)
+	for _, f := range fields {
+		if !f.isIface {
+			fmt.Fprintf(&body, "\tv.%s = raw.%s\n", f.goName, f.goName)
+			continue
+		}
+		if f.isList {
+			fmt.Fprintf(&body, "\tv.%s = make(%s, len(raw.%s))\n\tfor i, raw := range raw.%s {\n\t\tvv, err := unmarshal%s(raw)\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\tv.%s[i] = vv\n\t}\n", f.goName, f.goType, f.goName, f.goName, f.iface, f.goName)
+			continue
+		}
+		fmt.Fprintf(&body, "\tif len(raw.%s) > 0 && string(raw.%s) != \"null\" {\n\t\tvv, err := unmarshal%s(raw.%s)\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\tv.%s = vv\n\t}\n", f.goName, f.goName, f.iface, f.goName, f.goName)
+	}
+	body.WriteString("\treturn nil\n}\n")
+
+	return aux.String() + body.String()
+}
+
+// genWrapperFunc emits the typed `func OpName(ctx, client, variables)
+// (*OpNameResponse, error)` wrapper that sends op's query (plus any
+// fragments it depends on) and decodes the result.
+func (g *generator) genWrapperFunc(op *operation, varsType, respType string) (string, error) {
+	queryConst := lowerFirst(op.name) + "Query"
+	query, err := g.queryText(op)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "const %s = %s\n\n", queryConst, strconv.Quote(query))
+	fmt.Fprintf(&b, "// %s runs the %q %s.\nfunc %s(ctx context.Context, client *graphql.Client, variables %s) (*%s, error) {\n", op.name, op.name, op.kind, op.name, varsType, respType)
+	b.WriteString("\treq := graphql.NewRequest(" + queryConst + ")\n")
+	b.WriteString("\tvarBytes, err := json.Marshal(variables)\n\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"marshal variables: %w\", err)\n\t}\n")
+	b.WriteString("\tvar varMap map[string]interface{}\n\tif err := json.Unmarshal(varBytes, &varMap); err != nil {\n\t\treturn nil, fmt.Errorf(\"unmarshal variables: %w\", err)\n\t}\n")
+	b.WriteString("\tfor k, v := range varMap {\n\t\treq.Var(k, v)\n\t}\n")
+	fmt.Fprintf(&b, "\tvar resp %s\n\tif err := client.Run(ctx, req, &resp); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &resp, nil\n}\n", respType)
+	return b.String(), nil
+}
+
+// queryText assembles the document sent to the server: the operation's
+// own source, plus the source of every fragment it transitively spreads.
+func (g *generator) queryText(op *operation) (string, error) {
+	seen := map[string]bool{}
+	var names []string
+	var walk func(sels []selection) error
+	walk = func(sels []selection) error {
+		for _, sel := range sels {
+			if sel.fragmentSpread != "" {
+				if !seen[sel.fragmentSpread] {
+					seen[sel.fragmentSpread] = true
+					names = append(names, sel.fragmentSpread)
+					frag, ok := g.doc.fragments[sel.fragmentSpread]
+					if !ok {
+						return fmt.Errorf("unknown fragment %q", sel.fragmentSpread)
+					}
+					if err := walk(frag.selections); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := walk(sel.selections); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(op.selections); err != nil {
+		return "", err
+	}
+
+	parts := []string{op.rawText}
+	for _, n := range names {
+		parts = append(parts, g.doc.fragments[n].rawText)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// goFieldName converts a GraphQL name (camelCase or snake_case) to an
+// exported Go identifier.
+func goFieldName(name string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range name {
+		if r == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			b.WriteString(strings.ToUpper(string(r)))
+			upperNext = false
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+)
+
+const testSchema = `
+type Query {
+  user(id: ID!): User
+  search(term: String!): [SearchResult!]!
+}
+
+input CreateUserInput {
+  name: String!
+  role: Role!
+  nickname: String
+}
+
+enum Role {
+  ADMIN
+  MEMBER
+}
+
+type User {
+  id: ID!
+  name: String!
+  role: Role!
+  tags: [String!]
+}
+
+type Post {
+  id: ID!
+  title: String!
+}
+
+union SearchResult = User | Post
+`
+
+func TestGenerateObjectAndEnum(t *testing.T) {
+	sc, err := parseSchema(testSchema)
+	if err != nil {
+		t.Fatalf("parseSchema: %v", err)
+	}
+	doc, err := parseOperations(`
+		query GetUser($id: ID!) {
+			user(id: $id) {
+				...UserFields
+			}
+		}
+
+		fragment UserFields on User {
+			id
+			name
+			role
+			tags
+		}
+	`)
+	if err != nil {
+		t.Fatalf("parseOperations: %v", err)
+	}
+
+	src, err := generate(sc, doc, "api", "pointer")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := format.Source([]byte(src)); err != nil {
+		t.Fatalf("generated code is not valid Go: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"type GetUserVariables struct",
+		"type GetUserResponse struct",
+		"type GetUserResponseUser struct",
+		"Tags []string",
+		"Role Role",
+		"type Role string",
+		`RoleADMIN Role = "ADMIN"`,
+		"func GetUser(ctx context.Context, client *graphql.Client, variables GetUserVariables) (*GetUserResponse, error)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source missing %q\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateUnionInterface(t *testing.T) {
+	sc, err := parseSchema(testSchema)
+	if err != nil {
+		t.Fatalf("parseSchema: %v", err)
+	}
+	doc, err := parseOperations(`
+		query Search($term: String!) {
+			search(term: $term) {
+				... on User { id name }
+				... on Post { id title }
+			}
+		}
+	`)
+	if err != nil {
+		t.Fatalf("parseOperations: %v", err)
+	}
+
+	src, err := generate(sc, doc, "api", "pointer")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := format.Source([]byte(src)); err != nil {
+		t.Fatalf("generated code is not valid Go: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"type SearchResponseSearch interface",
+		"isSearchResponseSearch()",
+		"type SearchResponseSearchUser struct",
+		"type SearchResponseSearchPost struct",
+		"func unmarshalSearchResponseSearch(b []byte) (SearchResponseSearch, error)",
+		`case "User":`,
+		`case "Post":`,
+		"func (v *SearchResponse) UnmarshalJSON(b []byte) error",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source missing %q\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateInputAndOptional(t *testing.T) {
+	sc, err := parseSchema(testSchema)
+	if err != nil {
+		t.Fatalf("parseSchema: %v", err)
+	}
+	doc, err := parseOperations(`
+		query CreateUser($input: CreateUserInput!) {
+			user(id: "1") { id }
+		}
+	`)
+	if err != nil {
+		t.Fatalf("parseOperations: %v", err)
+	}
+
+	src, err := generate(sc, doc, "api", "optional")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := format.Source([]byte(src)); err != nil {
+		t.Fatalf("generated code is not valid Go: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"type CreateUserInput struct",
+		`Name string `,
+		`Nickname graphql.Optional[string]`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source missing %q\n%s", want, src)
+		}
+	}
+}
+
+func TestOperationNameRequired(t *testing.T) {
+	sc, err := parseSchema(testSchema)
+	if err != nil {
+		t.Fatalf("parseSchema: %v", err)
+	}
+	doc, err := parseOperations(`{ user(id: "1") { id } }`)
+	if err != nil {
+		t.Fatalf("parseOperations: %v", err)
+	}
+	if _, err := generate(sc, doc, "api", "pointer"); err == nil {
+		t.Fatal("expected an error for an anonymous operation")
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind identifies the kind of a lexed GraphQL document token. Only
+// the subset of the GraphQL grammar that generate.go needs is lexed;
+// string/int/float values are returned as opaque "value" tokens.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokName
+	tokPunct
+	tokValue
+)
+
+type token struct {
+	kind       tokenKind
+	text       string
+	start, end int // byte offsets into the source, for raw-text extraction
+}
+
+// lex tokenizes a GraphQL SDL or operation document, stripping whitespace,
+// commas (which the spec treats as insignificant) and "#" line comments.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	n := len(src)
+	for i < n {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ',':
+			i++
+		case c == '#':
+			for i < n && src[i] != '\n' {
+				i++
+			}
+		case c == '_' || isLetter(c):
+			start := i
+			for i < n && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			toks = append(toks, token{tokName, src[start:i], start, i})
+		case c == '"':
+			start := i
+			i++
+			// block string literals: """..."""
+			if i+1 < n && src[i] == '"' && src[i+1] == '"' {
+				i += 2
+				end := strings.Index(src[i:], `"""`)
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated block string starting at byte %d", start)
+				}
+				i += end + 3
+				toks = append(toks, token{tokValue, src[start:i], start, i})
+				continue
+			}
+			for i < n && src[i] != '"' {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= n {
+				return nil, fmt.Errorf("unterminated string starting at byte %d", start)
+			}
+			i++
+			toks = append(toks, token{tokValue, src[start:i], start, i})
+		case isDigit(c) || (c == '-' && i+1 < n && isDigit(src[i+1])):
+			start := i
+			i++
+			for i < n && (isDigit(src[i]) || src[i] == '.' || src[i] == 'e' || src[i] == 'E' || src[i] == '+' || src[i] == '-') {
+				i++
+			}
+			toks = append(toks, token{tokValue, src[start:i], start, i})
+		case c == '.' && i+2 < n && src[i+1] == '.' && src[i+2] == '.':
+			toks = append(toks, token{tokPunct, "...", i, i + 3})
+			i += 3
+		case strings.ContainsRune("!$():=@[]{|}&", rune(c)):
+			toks = append(toks, token{tokPunct, string(c), i, i + 1})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q at byte %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// tokStream is a cursor over a token slice with the small amount of
+// lookahead the schema/operation parsers need.
+type tokStream struct {
+	toks []token
+	pos  int
+}
+
+func (s *tokStream) peek() token {
+	if s.pos >= len(s.toks) {
+		return token{kind: tokEOF}
+	}
+	return s.toks[s.pos]
+}
+
+func (s *tokStream) next() token {
+	t := s.peek()
+	s.pos++
+	return t
+}
+
+// at reports whether the next token is a punctuator equal to p.
+func (s *tokStream) at(p string) bool {
+	t := s.peek()
+	return t.kind == tokPunct && t.text == p
+}
+
+// atName reports whether the next token is the keyword/name n.
+func (s *tokStream) atName(n string) bool {
+	t := s.peek()
+	return t.kind == tokName && t.text == n
+}
+
+func (s *tokStream) expectPunct(p string) error {
+	t := s.next()
+	if t.kind != tokPunct || t.text != p {
+		return fmt.Errorf("expected %q, got %q", p, t.text)
+	}
+	return nil
+}
+
+func (s *tokStream) expectName() (string, error) {
+	t := s.next()
+	if t.kind != tokName {
+		return "", fmt.Errorf("expected a name, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+// skipValue consumes one value: a scalar token, or a balanced [...] or
+// {...} group. Used to discard default values and directives, which
+// generate.go doesn't need.
+func (s *tokStream) skipValue() error {
+	t := s.peek()
+	switch {
+	case t.kind == tokValue:
+		s.pos++
+		return nil
+	case t.kind == tokName:
+		s.pos++
+		return nil
+	case t.kind == tokPunct && t.text == "$":
+		s.pos++
+		_, err := s.expectName()
+		return err
+	case t.kind == tokPunct && (t.text == "[" || t.text == "{"):
+		open, close := t.text, "]"
+		if open == "{" {
+			close = "}"
+		}
+		s.pos++
+		for !s.at(close) {
+			if s.peek().kind == tokEOF {
+				return fmt.Errorf("unterminated %s", open)
+			}
+			if s.at(":") {
+				s.pos++
+				continue
+			}
+			if err := s.skipValue(); err != nil {
+				return err
+			}
+		}
+		s.pos++
+		return nil
+	default:
+		return fmt.Errorf("expected a value, got %q", t.text)
+	}
+}
+
+// skipDirectives consumes zero or more "@name(args...)" directives,
+// which generate.go ignores.
+func (s *tokStream) skipDirectives() error {
+	for s.at("@") {
+		s.pos++
+		if _, err := s.expectName(); err != nil {
+			return err
+		}
+		if s.at("(") {
+			s.pos++
+			for !s.at(")") {
+				if _, err := s.expectName(); err != nil {
+					return err
+				}
+				if err := s.expectPunct(":"); err != nil {
+					return err
+				}
+				if err := s.skipValue(); err != nil {
+					return err
+				}
+			}
+			s.pos++
+		}
+	}
+	return nil
+}
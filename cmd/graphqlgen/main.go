@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "graphqlgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("graphqlgen", flag.ContinueOnError)
+	schemaPath := fs.String("schema", "", "path to the GraphQL schema, in SDL form")
+	outPath := fs.String("out", "", "path to write the generated Go source to (default: stdout)")
+	packageName := fs.String("package", "main", "package name for the generated file")
+	nullability := fs.String("nullability", "pointer", `how to represent nullable fields: "pointer" or "optional" (graphql.Optional[T])`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *schemaPath == "" {
+		return fmt.Errorf("-schema is required")
+	}
+	if *nullability != "pointer" && *nullability != "optional" {
+		return fmt.Errorf("-nullability must be %q or %q", "pointer", "optional")
+	}
+	opFiles := fs.Args()
+	if len(opFiles) == 0 {
+		return fmt.Errorf("at least one .graphql operation file is required")
+	}
+
+	schemaSrc, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+	sc, err := parseSchema(string(schemaSrc))
+	if err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+
+	doc := &document{fragments: map[string]*fragmentDef{}}
+	for _, path := range opFiles {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		d, err := parseOperations(string(src))
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		doc.operations = append(doc.operations, d.operations...)
+		for name, frag := range d.fragments {
+			doc.fragments[name] = frag
+		}
+	}
+
+	src, err := generate(sc, doc, *packageName, *nullability)
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return fmt.Errorf("generated code does not compile: %w\n\n%s", err, src)
+	}
+
+	if *outPath == "" {
+		_, err := os.Stdout.Write(formatted)
+		return err
+	}
+	return os.WriteFile(*outPath, formatted, 0o644)
+}
@@ -0,0 +1,270 @@
+package main
+
+import "fmt"
+
+// selection is one entry in a GraphQL selection set: a field, a named
+// fragment spread, or an inline fragment (with or without a type
+// condition).
+type selection struct {
+	alias          string // field: the result key (defaults to name)
+	name           string // field: the schema field name
+	selections     []selection
+	onType         string // inline fragment: the type condition, if any
+	fragmentSpread string // named fragment spread: the fragment's name
+}
+
+func (sel selection) isFragment() bool {
+	return sel.name == "" && sel.fragmentSpread == ""
+}
+
+type variableDef struct {
+	name string
+	typ  *typeRef
+}
+
+type operation struct {
+	kind       string // "query", "mutation" or "subscription"
+	name       string
+	vars       []variableDef
+	selections []selection
+	rawText    string // the operation's source text, verbatim
+}
+
+type fragmentDef struct {
+	name       string
+	on         string
+	selections []selection
+	rawText    string // the fragment's source text, verbatim
+}
+
+// document is a parsed .graphql operation file: the operations it
+// defines, plus any fragments they (transitively) spread.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragmentDef
+}
+
+// parseOperations parses a document containing one or more operations
+// and fragment definitions.
+func parseOperations(src string) (*document, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("lex operations: %w", err)
+	}
+	s := &tokStream{toks: toks}
+	doc := &document{fragments: map[string]*fragmentDef{}}
+	for s.peek().kind != tokEOF {
+		if err := skipDescription(s); err != nil {
+			return nil, err
+		}
+		if s.atName("fragment") {
+			startTok := s.pos
+			s.pos++
+			frag, err := parseFragmentDef(s)
+			if err != nil {
+				return nil, err
+			}
+			frag.rawText = rawText(src, s.toks, startTok, s.pos-1)
+			doc.fragments[frag.name] = frag
+			continue
+		}
+		startTok := s.pos
+		op, err := parseOperationDef(s)
+		if err != nil {
+			return nil, err
+		}
+		op.rawText = rawText(src, s.toks, startTok, s.pos-1)
+		doc.operations = append(doc.operations, op)
+	}
+	return doc, nil
+}
+
+// rawText returns the verbatim source text spanning tokens [from, to].
+func rawText(src string, toks []token, from, to int) string {
+	if from >= len(toks) || to >= len(toks) || to < from {
+		return ""
+	}
+	return src[toks[from].start:toks[to].end]
+}
+
+func parseFragmentDef(s *tokStream) (*fragmentDef, error) {
+	name, err := s.expectName()
+	if err != nil {
+		return nil, err
+	}
+	on, err := s.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if on != "on" {
+		return nil, fmt.Errorf("fragment %s: expected \"on\", got %q", name, on)
+	}
+	onType, err := s.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.skipDirectives(); err != nil {
+		return nil, err
+	}
+	sels, err := parseSelectionSet(s)
+	if err != nil {
+		return nil, err
+	}
+	return &fragmentDef{name: name, on: onType, selections: sels}, nil
+}
+
+func parseOperationDef(s *tokStream) (*operation, error) {
+	kind := "query"
+	if s.atName("query") || s.atName("mutation") || s.atName("subscription") {
+		kind, _ = s.expectName()
+	}
+	op := &operation{kind: kind}
+	if s.peek().kind == tokName {
+		name, err := s.expectName()
+		if err != nil {
+			return nil, err
+		}
+		op.name = name
+	}
+	if s.at("(") {
+		s.pos++
+		for !s.at(")") {
+			if err := s.expectPunct("$"); err != nil {
+				return nil, err
+			}
+			vname, err := s.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := s.expectPunct(":"); err != nil {
+				return nil, err
+			}
+			vtype, err := parseTypeRef(s)
+			if err != nil {
+				return nil, err
+			}
+			if s.at("=") {
+				s.pos++
+				if err := s.skipValue(); err != nil {
+					return nil, err
+				}
+			}
+			if err := s.skipDirectives(); err != nil {
+				return nil, err
+			}
+			op.vars = append(op.vars, variableDef{name: vname, typ: vtype})
+		}
+		s.pos++
+	}
+	if err := s.skipDirectives(); err != nil {
+		return nil, err
+	}
+	sels, err := parseSelectionSet(s)
+	if err != nil {
+		return nil, err
+	}
+	op.selections = sels
+	return op, nil
+}
+
+func parseSelectionSet(s *tokStream) ([]selection, error) {
+	if err := s.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var sels []selection
+	for !s.at("}") {
+		sel, err := parseSelection(s)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	s.pos++
+	return sels, nil
+}
+
+func parseSelection(s *tokStream) (selection, error) {
+	if s.at("...") {
+		s.pos++
+		if s.atName("on") {
+			s.pos++
+			onType, err := s.expectName()
+			if err != nil {
+				return selection{}, err
+			}
+			if err := s.skipDirectives(); err != nil {
+				return selection{}, err
+			}
+			inner, err := parseSelectionSet(s)
+			if err != nil {
+				return selection{}, err
+			}
+			return selection{onType: onType, selections: inner}, nil
+		}
+		if s.peek().kind == tokName {
+			name, err := s.expectName()
+			if err != nil {
+				return selection{}, err
+			}
+			if err := s.skipDirectives(); err != nil {
+				return selection{}, err
+			}
+			return selection{fragmentSpread: name}, nil
+		}
+		// anonymous inline fragment: no type condition
+		if err := s.skipDirectives(); err != nil {
+			return selection{}, err
+		}
+		inner, err := parseSelectionSet(s)
+		if err != nil {
+			return selection{}, err
+		}
+		return selection{selections: inner}, nil
+	}
+
+	name1, err := s.expectName()
+	if err != nil {
+		return selection{}, err
+	}
+	alias, fieldName := "", name1
+	if s.at(":") {
+		s.pos++
+		alias = name1
+		fieldName, err = s.expectName()
+		if err != nil {
+			return selection{}, err
+		}
+	}
+	sel := selection{name: fieldName}
+	if alias != "" {
+		sel.alias = alias
+	} else {
+		sel.alias = fieldName
+	}
+	if s.at("(") {
+		s.pos++
+		for !s.at(")") {
+			if _, err := s.expectName(); err != nil {
+				return selection{}, err
+			}
+			if err := s.expectPunct(":"); err != nil {
+				return selection{}, err
+			}
+			if err := s.skipValue(); err != nil {
+				return selection{}, err
+			}
+		}
+		s.pos++
+	}
+	if err := s.skipDirectives(); err != nil {
+		return selection{}, err
+	}
+	if s.at("{") {
+		inner, err := parseSelectionSet(s)
+		if err != nil {
+			return selection{}, err
+		}
+		sel.selections = inner
+	}
+	return sel, nil
+}
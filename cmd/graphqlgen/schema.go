@@ -0,0 +1,477 @@
+package main
+
+import "fmt"
+
+// typeRef is a GraphQL type reference, e.g. "[String!]!": a chain of
+// "list of" wrappers around a named leaf type, with non-null tracked at
+// each level.
+type typeRef struct {
+	name    string // leaf named type; empty when listOf != nil
+	listOf  *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	var s string
+	if t.listOf != nil {
+		s = "[" + t.listOf.String() + "]"
+	} else {
+		s = t.name
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type argDef struct {
+	name string
+	typ  *typeRef
+}
+
+type fieldDef struct {
+	name string
+	args []argDef
+	typ  *typeRef
+}
+
+type objectDef struct {
+	name       string
+	implements []string
+	fields     []fieldDef
+}
+
+type inputDef struct {
+	name   string
+	fields []fieldDef
+}
+
+type enumDef struct {
+	name   string
+	values []string
+}
+
+type interfaceDef struct {
+	name   string
+	fields []fieldDef
+}
+
+type unionDef struct {
+	name    string
+	members []string
+}
+
+// schema is the subset of a GraphQL schema graphqlgen needs: enough to
+// resolve the type of every field an operation selects.
+type schema struct {
+	objects    map[string]*objectDef
+	inputs     map[string]*inputDef
+	enums      map[string]*enumDef
+	interfaces map[string]*interfaceDef
+	unions     map[string]*unionDef
+	scalars    map[string]bool
+	queryType  string
+	mutType    string
+	subType    string
+}
+
+func newSchema() *schema {
+	return &schema{
+		objects:    map[string]*objectDef{},
+		inputs:     map[string]*inputDef{},
+		enums:      map[string]*enumDef{},
+		interfaces: map[string]*interfaceDef{},
+		unions:     map[string]*unionDef{},
+		// the five built-in scalars are always available
+		scalars:   map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true},
+		queryType: "Query", mutType: "Mutation", subType: "Subscription",
+	}
+}
+
+// parseSchema parses a GraphQL SDL document (as produced by, e.g.,
+// `graphql-inspector` or a server's schema dump). Introspection JSON is
+// not supported; convert it to SDL first.
+func parseSchema(src string) (*schema, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("lex schema: %w", err)
+	}
+	s := &tokStream{toks: toks}
+	sc := newSchema()
+	for s.peek().kind != tokEOF {
+		if err := parseSchemaDefinition(s, sc); err != nil {
+			return nil, err
+		}
+	}
+	return sc, nil
+}
+
+func parseSchemaDefinition(s *tokStream, sc *schema) error {
+	if err := skipDescription(s); err != nil {
+		return err
+	}
+	kw, err := s.expectName()
+	if err != nil {
+		return err
+	}
+	switch kw {
+	case "schema":
+		return parseSchemaBlock(s, sc)
+	case "type":
+		def, err := parseObjectLike(s)
+		if err != nil {
+			return err
+		}
+		sc.objects[def.name] = def
+	case "interface":
+		def, err := parseObjectLike(s)
+		if err != nil {
+			return err
+		}
+		sc.interfaces[def.name] = &interfaceDef{name: def.name, fields: def.fields}
+	case "input":
+		def, err := parseObjectLike(s)
+		if err != nil {
+			return err
+		}
+		sc.inputs[def.name] = &inputDef{name: def.name, fields: def.fields}
+	case "enum":
+		return parseEnum(s, sc)
+	case "union":
+		return parseUnion(s, sc)
+	case "scalar":
+		name, err := s.expectName()
+		if err != nil {
+			return err
+		}
+		if err := s.skipDirectives(); err != nil {
+			return err
+		}
+		sc.scalars[name] = true
+	case "directive":
+		return skipDirectiveDefinition(s)
+	case "extend":
+		// "extend type X { ... }" etc.: re-dispatch on the next keyword,
+		// merging fields into the existing definition where relevant is
+		// not implemented; we just parse and discard, since operations
+		// rarely depend on an extension's extra fields alone.
+		return parseSchemaDefinition(s, newSchema())
+	default:
+		return fmt.Errorf("unexpected schema keyword %q", kw)
+	}
+	return nil
+}
+
+// skipDescription consumes an optional leading string/block-string
+// description, which GraphQL SDL allows before any definition.
+func skipDescription(s *tokStream) error {
+	if s.peek().kind == tokValue {
+		s.pos++
+	}
+	return nil
+}
+
+func parseSchemaBlock(s *tokStream, sc *schema) error {
+	if err := s.expectPunct("{"); err != nil {
+		return err
+	}
+	for !s.at("}") {
+		op, err := s.expectName()
+		if err != nil {
+			return err
+		}
+		if err := s.expectPunct(":"); err != nil {
+			return err
+		}
+		name, err := s.expectName()
+		if err != nil {
+			return err
+		}
+		switch op {
+		case "query":
+			sc.queryType = name
+		case "mutation":
+			sc.mutType = name
+		case "subscription":
+			sc.subType = name
+		}
+	}
+	s.pos++
+	return nil
+}
+
+// parseObjectLike parses the shared "Name [implements I & J] { fields }"
+// shape used by `type` and `interface` definitions.
+func parseObjectLike(s *tokStream) (*objectDef, error) {
+	name, err := s.expectName()
+	if err != nil {
+		return nil, err
+	}
+	def := &objectDef{name: name}
+	if s.atName("implements") {
+		s.pos++
+		for {
+			iface, err := s.expectName()
+			if err != nil {
+				return nil, err
+			}
+			def.implements = append(def.implements, iface)
+			if s.at("&") {
+				s.pos++
+				continue
+			}
+			break
+		}
+	}
+	if err := s.skipDirectives(); err != nil {
+		return nil, err
+	}
+	if !s.at("{") {
+		// a type/interface with no fields, e.g. a marker type
+		return def, nil
+	}
+	s.pos++
+	for !s.at("}") {
+		if err := skipDescription(s); err != nil {
+			return nil, err
+		}
+		f, err := parseFieldDef(s)
+		if err != nil {
+			return nil, err
+		}
+		def.fields = append(def.fields, f)
+	}
+	s.pos++
+	return def, nil
+}
+
+func parseFieldDef(s *tokStream) (fieldDef, error) {
+	name, err := s.expectName()
+	if err != nil {
+		return fieldDef{}, err
+	}
+	f := fieldDef{name: name}
+	if s.at("(") {
+		s.pos++
+		for !s.at(")") {
+			if err := skipDescription(s); err != nil {
+				return fieldDef{}, err
+			}
+			argName, err := s.expectName()
+			if err != nil {
+				return fieldDef{}, err
+			}
+			if err := s.expectPunct(":"); err != nil {
+				return fieldDef{}, err
+			}
+			argType, err := parseTypeRef(s)
+			if err != nil {
+				return fieldDef{}, err
+			}
+			if s.at("=") {
+				s.pos++
+				if err := s.skipValue(); err != nil {
+					return fieldDef{}, err
+				}
+			}
+			if err := s.skipDirectives(); err != nil {
+				return fieldDef{}, err
+			}
+			f.args = append(f.args, argDef{name: argName, typ: argType})
+		}
+		s.pos++
+	}
+	if err := s.expectPunct(":"); err != nil {
+		return fieldDef{}, err
+	}
+	typ, err := parseTypeRef(s)
+	if err != nil {
+		return fieldDef{}, err
+	}
+	f.typ = typ
+	if err := s.skipDirectives(); err != nil {
+		return fieldDef{}, err
+	}
+	return f, nil
+}
+
+func parseTypeRef(s *tokStream) (*typeRef, error) {
+	var t *typeRef
+	if s.at("[") {
+		s.pos++
+		inner, err := parseTypeRef(s)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.expectPunct("]"); err != nil {
+			return nil, err
+		}
+		t = &typeRef{listOf: inner}
+	} else {
+		name, err := s.expectName()
+		if err != nil {
+			return nil, err
+		}
+		t = &typeRef{name: name}
+	}
+	if s.at("!") {
+		s.pos++
+		t.nonNull = true
+	}
+	return t, nil
+}
+
+func parseEnum(s *tokStream, sc *schema) error {
+	name, err := s.expectName()
+	if err != nil {
+		return err
+	}
+	if err := s.skipDirectives(); err != nil {
+		return err
+	}
+	def := &enumDef{name: name}
+	if s.at("{") {
+		s.pos++
+		for !s.at("}") {
+			if err := skipDescription(s); err != nil {
+				return err
+			}
+			v, err := s.expectName()
+			if err != nil {
+				return err
+			}
+			if err := s.skipDirectives(); err != nil {
+				return err
+			}
+			def.values = append(def.values, v)
+		}
+		s.pos++
+	}
+	sc.enums[name] = def
+	return nil
+}
+
+func parseUnion(s *tokStream, sc *schema) error {
+	name, err := s.expectName()
+	if err != nil {
+		return err
+	}
+	if err := s.skipDirectives(); err != nil {
+		return err
+	}
+	def := &unionDef{name: name}
+	if s.at("=") {
+		s.pos++
+		for {
+			if s.at("|") {
+				s.pos++
+			}
+			member, err := s.expectName()
+			if err != nil {
+				return err
+			}
+			def.members = append(def.members, member)
+			if !s.at("|") {
+				break
+			}
+		}
+	}
+	sc.unions[name] = def
+	return nil
+}
+
+// skipDirectiveDefinition discards a "directive @name(...) on LOCATION |
+// LOCATION" definition, which generate.go has no use for.
+func skipDirectiveDefinition(s *tokStream) error {
+	if err := s.expectPunct("@"); err != nil {
+		return err
+	}
+	if _, err := s.expectName(); err != nil {
+		return err
+	}
+	if s.at("(") {
+		s.pos++
+		depth := 1
+		for depth > 0 {
+			t := s.next()
+			if t.kind == tokEOF {
+				return fmt.Errorf("unterminated directive definition")
+			}
+			if t.kind == tokPunct && t.text == "(" {
+				depth++
+			}
+			if t.kind == tokPunct && t.text == ")" {
+				depth--
+			}
+		}
+	}
+	if s.atName("repeatable") {
+		s.pos++
+	}
+	if s.atName("on") {
+		s.pos++
+		for {
+			if _, err := s.expectName(); err != nil {
+				return err
+			}
+			if s.at("|") {
+				s.pos++
+				continue
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// fieldsOf returns the selectable fields of any named composite type
+// (object or interface), or nil for scalars/enums/unions, which union
+// member selections reach only through inline fragments.
+func (sc *schema) fieldsOf(typeName string) []fieldDef {
+	if o, ok := sc.objects[typeName]; ok {
+		return o.fields
+	}
+	if i, ok := sc.interfaces[typeName]; ok {
+		return i.fields
+	}
+	return nil
+}
+
+func (sc *schema) isEnum(typeName string) bool {
+	_, ok := sc.enums[typeName]
+	return ok
+}
+
+func (sc *schema) isScalar(typeName string) bool {
+	return sc.scalars[typeName]
+}
+
+func (sc *schema) isUnion(typeName string) bool {
+	_, ok := sc.unions[typeName]
+	return ok
+}
+
+func (sc *schema) isInterface(typeName string) bool {
+	_, ok := sc.interfaces[typeName]
+	return ok
+}
+
+// abstractMembers returns the concrete object type names that can be
+// returned for an interface or union type.
+func (sc *schema) abstractMembers(typeName string) []string {
+	if u, ok := sc.unions[typeName]; ok {
+		return u.members
+	}
+	if _, ok := sc.interfaces[typeName]; ok {
+		var members []string
+		for _, o := range sc.objects {
+			for _, impl := range o.implements {
+				if impl == typeName {
+					members = append(members, o.name)
+				}
+			}
+		}
+		return members
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorsNameDecodesAndClassifies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"errors": [{
+				"message": "object not found",
+				"name": "not_found",
+				"data": {"objectId": "123"}
+			}]
+		}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	var gerrs Errors
+	assert.True(t, errors.As(err, &gerrs))
+	assert.Equal(t, ErrNotFound, gerrs[0].Name)
+	assert.False(t, gerrs.IsRetryable())
+}
+
+func TestErrorsIsRetryable(t *testing.T) {
+	cases := []struct {
+		name      string
+		errs      Errors
+		retryable bool
+	}{
+		{"empty", nil, false},
+		{"not found", Errors{{Name: ErrNotFound}}, false},
+		{"capacity exceeded", Errors{{Name: ErrCapacityExceeded}}, true},
+		{"service unavailable", Errors{{Name: ErrServiceUnavailable}}, true},
+		{"service failure", Errors{{Name: ErrServiceFailure}}, true},
+		{"internal error", Errors{{Name: ErrInternal}}, true},
+		{"mixed", Errors{{Name: ErrNotFound}, {Name: ErrInternal}}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.retryable, c.errs.IsRetryable())
+		})
+	}
+}
@@ -38,12 +38,16 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 	"io"
-	"log"
+	"math/rand"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Client is a client for interacting with a GraphQL API.
@@ -52,9 +56,67 @@ type Client struct {
 	httpClient       *http.Client
 	useMultipartForm bool
 
+	// subscriptionProtocol selects the websocket subprotocol used by
+	// SubscriptionClient. Defaults to ProtocolGraphQLWS.
+	subscriptionProtocol string
+
+	// subscriptionMinBackoff and subscriptionMaxBackoff bound the
+	// exponential backoff used when SubscriptionClient reconnects.
+	subscriptionMinBackoff time.Duration
+	subscriptionMaxBackoff time.Duration
+
+	// subscriptionKeepaliveTimeout, if set, closes the subscription
+	// connection (triggering a reconnect) when no message, including
+	// keepalives, is received within the window.
+	subscriptionKeepaliveTimeout time.Duration
+
+	// subscriptionPingInterval overrides how often a graphql-transport-ws
+	// connection sends a client ping. See WithKeepAlivePing.
+	subscriptionPingInterval time.Duration
+
+	// subscriptionDialer, if set, is used instead of websocket.DefaultDialer
+	// to open subscription connections. See WithWebsocketDialer.
+	subscriptionDialer *websocket.Dialer
+
+	// subscriptionInitPayload, if set, is marshaled and sent alongside
+	// connection_init when opening a subscription connection, e.g. an
+	// auth token the server expects. See WithConnectionInitPayload.
+	subscriptionInitPayload interface{}
+
+	// usePersistedQueries enables Automatic Persisted Queries (APQ).
+	usePersistedQueries bool
+
+	// persistedQueryCache stores the sha256 hash computed for each query
+	// text, keyed by the query itself, so it is shared across requests
+	// (and, if the caller supplies their own, across Clients). Defaults
+	// to an in-memory cache private to this Client. See
+	// WithPersistedQueryCache.
+	persistedQueryCache PersistedQueryCache
+
+	// apqOverGET sends Automatic Persisted Queries as GETs instead of
+	// POSTs, so a CDN in front of the server can cache the response.
+	// See WithAPQOverGET.
+	apqOverGET bool
+
+	// middlewareMu guards middleware, so Use can be called concurrently
+	// with Run.
+	middlewareMu sync.RWMutex
+
+	// middleware wraps every Client.Run call, outermost first. See
+	// WithMiddleware and Use.
+	middleware []Middleware
+
+	// interceptors wraps the JSON and multipart transports, outermost
+	// first. See WithInterceptors.
+	interceptors []RequestInterceptor
+
 	// closeReq will close the request body immediately allowing for reuse of client
 	closeReq bool
 
+	// batcher, if set, coalesces concurrent Run calls into RunBatch
+	// calls. See WithBatchInterval.
+	batcher *batcher
+
 	// Log is called with various debug information.
 	// To log to standard out, use:
 	//  client.Log = func(s string) { log.Println(s) }
@@ -74,6 +136,9 @@ func NewClient(endpoint string, opts ...ClientOption) *Client {
 	if c.httpClient == nil {
 		c.httpClient = http.DefaultClient
 	}
+	if c.usePersistedQueries && c.persistedQueryCache == nil {
+		c.persistedQueryCache = newMemPersistedQueryCache()
+	}
 	return c
 }
 
@@ -95,6 +160,40 @@ func (c *Client) Run(ctx context.Context, req *Request, resp interface{}) error
 	if len(req.files) > 0 && !c.useMultipartForm {
 		return errors.New("cannot send files with PostFields option")
 	}
+	c.middlewareMu.RLock()
+	mw := c.middleware
+	c.middlewareMu.RUnlock()
+
+	handler := c.dispatch
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
+	}
+	return handler(ctx, req, resp)
+}
+
+// Use appends middleware to the chain that wraps every Run call, exactly
+// like WithMiddleware, but can be called at any point in the Client's
+// life, including concurrently with Run. It's useful for middleware that
+// can only be built once the Client itself exists, such as one that
+// refers back to c.
+func (c *Client) Use(mw ...Middleware) {
+	c.middlewareMu.Lock()
+	defer c.middlewareMu.Unlock()
+	c.middleware = append(c.middleware, mw...)
+}
+
+// dispatch is the innermost Handler in the middleware chain: it performs
+// the request using whichever transport the Client was configured with.
+func (c *Client) dispatch(ctx context.Context, req *Request, resp interface{}) error {
+	if c.batcher != nil {
+		if len(c.interceptors) > 0 {
+			return errors.New("graphql: WithBatchInterval is incompatible with WithInterceptors/WithRetry/WithBearerToken: RunBatch has no per-request RoundTrip for them to wrap")
+		}
+		return c.batcher.run(ctx, req, resp)
+	}
+	if c.usePersistedQueries {
+		return c.runWithPersistedQuery(ctx, req, resp)
+	}
 	if c.useMultipartForm {
 		return c.runWithPostFields(ctx, req, resp)
 	}
@@ -102,6 +201,16 @@ func (c *Client) Run(ctx context.Context, req *Request, resp interface{}) error
 }
 
 func (c *Client) runWithJSON(ctx context.Context, req *Request, resp interface{}) error {
+	result, err := c.chainInterceptors(c.jsonRoundTrip)(ctx, req)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(result, resp)
+}
+
+// jsonRoundTrip is the terminal RoundTrip for the default transport: it
+// encodes req as a JSON POST body and performs the HTTP call.
+func (c *Client) jsonRoundTrip(ctx context.Context, req *Request) (*Response, error) {
 	var requestBody bytes.Buffer
 	requestBodyObj := struct {
 		Query     string                 `json:"query"`
@@ -111,18 +220,14 @@ func (c *Client) runWithJSON(ctx context.Context, req *Request, resp interface{}
 		Variables: req.vars,
 	}
 	if err := json.NewEncoder(&requestBody).Encode(requestBodyObj); err != nil {
-		return errors.Wrap(err, "encode body")
+		return nil, errors.Wrap(err, "encode body")
 	}
 	c.logf(">> variables: %v", req.vars)
 	c.logf(">> query: %s", req.q)
-	gr := &graphResponse{
-		Data: resp,
-	}
 	r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	r.Close = c.closeReq
 	r.Header.Set("Content-Type", "application/json; charset=utf-8")
 	r.Header.Set("Accept", "application/json; charset=utf-8")
 	for key, values := range req.Header {
@@ -131,69 +236,78 @@ func (c *Client) runWithJSON(ctx context.Context, req *Request, resp interface{}
 		}
 	}
 	c.logf(">> headers: %v", r.Header)
-	r = r.WithContext(ctx)
-	res, err := c.httpClient.Do(r)
+	return c.doHTTP(ctx, r)
+}
+
+func (c *Client) runWithPostFields(ctx context.Context, req *Request, resp interface{}) error {
+	result, err := c.chainInterceptors(c.postFieldsRoundTrip)(ctx, req)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, res.Body); err != nil {
-		return errors.Wrap(err, "reading body")
-	}
-	c.logf("<< %s", buf.String())
-	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
-		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("graphql: server returned a non-200 status code: %v", res.StatusCode)
-		}
-		return errors.Wrap(err, "decoding response")
-	}
-	if len(gr.Errors) > 0 {
-		// return first error
-		return gr.Errors[0]
-	}
-	return nil
+	return decodeResponse(result, resp)
 }
 
-func (c *Client) runWithPostFields(ctx context.Context, req *Request, resp interface{}) error {
+// postFieldsRoundTrip is the terminal RoundTrip for the multipart
+// transport. It implements the GraphQL multipart request spec
+// (https://github.com/jaydenseric/graphql-multipart-request-spec): an
+// "operations" field holding the query and variables with a null
+// placeholder at every file's position, a "map" field pointing each
+// numbered file part back at the variable path it belongs to, and the
+// file parts themselves, named "0", "1", ... in File() call order.
+func (c *Client) postFieldsRoundTrip(ctx context.Context, req *Request) (*Response, error) {
 	var requestBody bytes.Buffer
 	writer := multipart.NewWriter(&requestBody)
-	if err := writer.WriteField("query", req.q); err != nil {
-		return errors.Wrap(err, "write query field")
+
+	variables := cloneVariables(req.vars)
+	fileMap := make(map[string][]string, len(req.files))
+	for i := range req.files {
+		idx := strconv.Itoa(i)
+		fileMap[idx] = []string{req.files[i].Field}
+		variables = setVariableNull(variables, req.files[i].Field)
 	}
-	var variablesBuf bytes.Buffer
-	if len(req.vars) > 0 {
-		variablesField, err := writer.CreateFormField("variables")
-		if err != nil {
-			return errors.Wrap(err, "create variables field")
-		}
-		if err := json.NewEncoder(io.MultiWriter(variablesField, &variablesBuf)).Encode(req.vars); err != nil {
-			return errors.Wrap(err, "encode variables")
-		}
+
+	operations := struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}{
+		Query:     req.q,
+		Variables: variables,
+	}
+	operationsJSON, err := json.Marshal(operations)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode operations")
+	}
+	if err := writer.WriteField("operations", string(operationsJSON)); err != nil {
+		return nil, errors.Wrap(err, "write operations field")
+	}
+
+	mapJSON, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode map")
 	}
+	if err := writer.WriteField("map", string(mapJSON)); err != nil {
+		return nil, errors.Wrap(err, "write map field")
+	}
+
 	for i := range req.files {
-		part, err := writer.CreateFormFile(req.files[i].Field, req.files[i].Name)
+		part, err := createFormFile(writer, strconv.Itoa(i), req.files[i].Name)
 		if err != nil {
-			return errors.Wrap(err, "create form file")
+			return nil, errors.Wrap(err, "create form file")
 		}
 		if _, err := io.Copy(part, req.files[i].R); err != nil {
-			return errors.Wrap(err, "preparing file")
+			return nil, errors.Wrap(err, "preparing file")
 		}
 	}
 	if err := writer.Close(); err != nil {
-		return errors.Wrap(err, "close writer")
+		return nil, errors.Wrap(err, "close writer")
 	}
-	c.logf(">> variables: %s", variablesBuf.String())
+	c.logf(">> operations: %s", operationsJSON)
+	c.logf(">> map: %s", mapJSON)
 	c.logf(">> files: %d", len(req.files))
-	c.logf(">> query: %s", req.q)
-	gr := &graphResponse{
-		Data: resp,
-	}
 	r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	r.Close = c.closeReq
 	r.Header.Set("Content-Type", writer.FormDataContentType())
 	r.Header.Set("Accept", "application/json; charset=utf-8")
 	for key, values := range req.Header {
@@ -202,28 +316,81 @@ func (c *Client) runWithPostFields(ctx context.Context, req *Request, resp inter
 		}
 	}
 	c.logf(">> headers: %v", r.Header)
-	r = r.WithContext(ctx)
-	res, err := c.httpClient.Do(r)
+	return c.doHTTP(ctx, r)
+}
+
+// cloneVariables returns a deep copy of vars, safe for setVariableNull to
+// mutate without affecting the Request it came from.
+func cloneVariables(vars map[string]interface{}) map[string]interface{} {
+	clone := map[string]interface{}{}
+	if len(vars) == 0 {
+		return clone
+	}
+	b, err := json.Marshal(vars)
 	if err != nil {
-		return err
+		return clone
 	}
-	defer res.Body.Close()
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, res.Body); err != nil {
-		return errors.Wrap(err, "reading body")
+	if err := json.Unmarshal(b, &clone); err != nil {
+		return clone
 	}
-	c.logf("<< %s", buf.String())
-	if err := json.NewDecoder(&buf).Decode(&gr); err != nil {
-		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("graphql: server returned a non-200 status code: %v", res.StatusCode)
+	return clone
+}
+
+// setVariableNull sets the value at path (a File.Field, e.g.
+// "variables.avatar" or "variables.files.0") to nil within vars,
+// creating intermediate maps and list elements as needed so operations
+// marshals the null placeholder the multipart spec requires.
+func setVariableNull(vars map[string]interface{}, path string) map[string]interface{} {
+	segments := strings.Split(strings.TrimPrefix(path, "variables."), ".")
+	result := setValueAtPath(vars, segments)
+	m, _ := result.(map[string]interface{})
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	return m
+}
+
+// setValueAtPath sets nil at the position segments describes within
+// container, a map[string]interface{} or []interface{} built from JSON,
+// and returns the (possibly replaced) container.
+func setValueAtPath(container interface{}, segments []string) interface{} {
+	if len(segments) == 0 {
+		return nil
+	}
+	seg, rest := segments[0], segments[1:]
+	if i, err := strconv.Atoi(seg); err == nil {
+		list, _ := container.([]interface{})
+		for len(list) <= i {
+			list = append(list, nil)
 		}
-		return errors.Wrap(err, "decoding response")
+		list[i] = setValueAtPath(list[i], rest)
+		return list
 	}
-	if len(gr.Errors) > 0 {
-		// return first error
-		return gr.Errors[0]
+	m, _ := container.(map[string]interface{})
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	m[seg] = setValueAtPath(m[seg], rest)
+	return m
+}
+
+// quoteEscaper matches the unexported one mime/multipart uses internally
+// to sanitise the quoted strings in a Content-Disposition header.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// createFormFile is like multipart.Writer.CreateFormFile, except it sets
+// Content-Type from the file's extension instead of always using
+// application/octet-stream, per the multipart spec's "proper" part.
+func createFormFile(w *multipart.Writer, fieldname, filename string) (io.Writer, error) {
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
 	}
-	return nil
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldname), quoteEscaper.Replace(filename)))
+	h.Set("Content-Type", contentType)
+	return w.CreatePart(h)
 }
 
 // WithHTTPClient specifies the underlying http.Client to use when
@@ -250,21 +417,128 @@ func ImmediatelyCloseReqBody() ClientOption {
 	}
 }
 
+// WithPersistedQueries enables Automatic Persisted Queries (APQ): requests
+// are first sent as a SHA-256 hash of the query, falling back to a
+// request carrying the full query (which registers the hash) only on a
+// cache miss. Not compatible with UseMultipartForm. Computed hashes are
+// cached in an in-memory PersistedQueryCache private to this Client; use
+// WithPersistedQueryCache to pre-populate query hashes or share a cache
+// across Clients. See WithAPQOverGET to send both attempts as GETs
+// instead of POSTs.
+func WithPersistedQueries() ClientOption {
+	return func(client *Client) {
+		client.usePersistedQueries = true
+	}
+}
+
+// WithAPQOverGET makes Automatic Persisted Queries (see
+// WithPersistedQueries) send both the hash-only attempt and the
+// registering retry as GETs with the query-string params, rather than
+// POSTs, so a CDN in front of the server can cache the response. This
+// only applies to GET-safe requests: a mutation is always sent over
+// POST regardless of this option, since the GraphQL-over-HTTP GET
+// contract requires the request be side-effect-free.
+func WithAPQOverGET() ClientOption {
+	return func(client *Client) {
+		client.usePersistedQueries = true
+		client.apqOverGET = true
+	}
+}
+
+// WithPersistedQueryCache enables Automatic Persisted Queries, as
+// WithPersistedQueries does, using cache in place of the default
+// in-memory one. Passing a cache shared by several Clients, or one
+// pre-populated with known query hashes, lets them skip computing a
+// query's hash. It does not change what is sent: the hash-only attempt
+// always comes first, and a PersistedQueryNotFound reply still triggers
+// the registering retry.
+func WithPersistedQueryCache(cache PersistedQueryCache) ClientOption {
+	return func(client *Client) {
+		client.usePersistedQueries = true
+		client.persistedQueryCache = cache
+	}
+}
+
 // ClientOption are functions that are passed into NewClient to
 // modify the behaviour of the Client.
 type ClientOption func(*Client)
 
-type graphErr struct {
-	Message string
+// ErrorLocation is the line/column in the query document that an Error
+// is associated with.
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
 }
 
-func (e graphErr) Error() string {
+// Error is a single error returned by a GraphQL server, per the GraphQL
+// spec: https://spec.graphql.org/draft/#sec-Errors, extended with the
+// Name, Data and TimeThrown fields some servers (including this one)
+// populate alongside the spec-mandated ones.
+type Error struct {
+	Message    string                 `json:"message,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Locations  []ErrorLocation        `json:"locations,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// Name classifies the error, e.g. ErrCapacityExceeded, so callers
+	// can branch on it without string-matching Message. See IsRetryable.
+	Name ErrCode `json:"name,omitempty"`
+	// Data carries server-specific detail about the error, such as the
+	// ID of the object that caused it.
+	Data interface{} `json:"data,omitempty"`
+	// TimeThrown is the server-reported time the error occurred.
+	TimeThrown string `json:"time_thrown,omitempty"`
+}
+
+func (e Error) Error() string {
 	return "graphql: " + e.Message
 }
 
+// Errors is returned by Client.Run whenever the server responds with a
+// non-empty errors array. Its Error method reports the first message, for
+// backward compatibility, but every error is reachable:
+//
+//	var gerrs graphql.Errors
+//	if errors.As(err, &gerrs) {
+//	    for _, e := range gerrs { ... }
+//	}
+type Errors []Error
+
+func (e Errors) Error() string {
+	if len(e) == 0 {
+		return "graphql: unknown error"
+	}
+	return e[0].Error()
+}
+
+// Unwrap exposes each Error so errors.Is and errors.As can traverse them.
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+	return errs
+}
+
+// IsRetryable reports whether any error in e is one this server marks
+// as a transient failure (ErrCapacityExceeded, ErrServiceUnavailable,
+// ErrServiceFailure, ErrInternal) worth retrying, rather than a
+// permanent one like ErrNotFound or ErrInvalidInput. WithRetry uses
+// this to decide whether to retry a GraphQL-level error; callers
+// building their own retry policy can call it directly.
+func (e Errors) IsRetryable() bool {
+	for _, err := range e {
+		switch err.Name {
+		case ErrCapacityExceeded, ErrServiceUnavailable, ErrServiceFailure, ErrInternal:
+			return true
+		}
+	}
+	return false
+}
+
 type graphResponse struct {
 	Data   interface{}
-	Errors []graphErr
+	Errors Errors
 }
 
 // Request is a GraphQL request.
@@ -276,6 +550,9 @@ type Request struct {
 	// Header represent any request headers that will be set
 	// when the request is made.
 	Header http.Header
+
+	// persistedQueryHash caches the APQ sha256 hash of q, computed lazily.
+	persistedQueryHash string
 }
 
 // NewRequest makes a new Request with the specified string.
@@ -310,9 +587,12 @@ func (req *Request) Query() string {
 	return req.q
 }
 
-// File sets a file to upload.
-// Files are only supported with a Client that was created with
-// the UseMultipartForm option.
+// File sets a file to upload. fieldname is the dot-path, rooted at
+// "variables", of the position in the request's variables that the file
+// belongs to, e.g. req.File("variables.avatar", "photo.png", r) for a
+// scalar variable or req.File("variables.files.0", "photo.png", r) for
+// the first element of a list variable. Files are only supported with a
+// Client that was created with the UseMultipartForm option.
 func (req *Request) File(fieldname, filename string, r io.Reader) {
 	req.files = append(req.files, File{
 		Field: fieldname,
@@ -321,7 +601,8 @@ func (req *Request) File(fieldname, filename string, r io.Reader) {
 	})
 }
 
-// File represents a file to upload.
+// File represents a file to upload. Field is the variable path described
+// in Request.File.
 type File struct {
 	Field string
 	Name  string
@@ -330,17 +611,75 @@ type File struct {
 
 
 type SubscriptionClient struct {
+	connMu       sync.RWMutex
+	writeMu      sync.Mutex
+	subWebsocket *websocket.Conn
+	subBuffer    chan subscriptionMessage
+	subWait      sync.WaitGroup
+	subs         sync.Map // id (string) -> *subscriptionEntry
+	subIdGen     int
+	protocol     string
+
+	// dialer, if non-nil, overrides websocket.DefaultDialer. See
+	// WithWebsocketDialer.
+	dialer *websocket.Dialer
+
+	// initPayload, if non-nil, is marshaled and sent alongside
+	// connection_init. See WithConnectionInitPayload.
+	initPayload interface{}
+
+	// connAckPayload holds the payload sent by the server alongside
+	// connection_ack, if any.
+	connAckPayload *json.RawMessage
+
+	ctx      context.Context
+	header   http.Header
+	endpoint string
+
+	minBackoff       time.Duration
+	maxBackoff       time.Duration
+	keepaliveTimeout time.Duration
+	pingInterval     time.Duration
+
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// errCh carries non-fatal errors encountered while reconnecting, for
+	// callers that want visibility without tearing down their subscriptions.
+	errCh chan error
+}
 
-	subWebsocket * websocket.Conn
-	subBuffer chan subscriptionMessage
-	subWait sync.WaitGroup
-	subs sync.Map
-	subIdGen int
+// subscriptionEntry tracks a live subscription so it can be re-sent to the
+// server after a reconnect.
+type subscriptionEntry struct {
+	req *Request
+	ch  Subscription
 }
 
+// Errors returns a channel of non-fatal errors encountered while
+// maintaining the subscription connection (e.g. failed reconnect
+// attempts). It is never closed.
+func (c *SubscriptionClient) Errors() <-chan error {
+	return c.errCh
+}
+
+// ConnectionAckPayload returns the payload the server sent with
+// connection_ack, or nil if none was sent.
+func (c *SubscriptionClient) ConnectionAckPayload() *json.RawMessage {
+	return c.connAckPayload
+}
 
 type subscriptionMessageType string
 
+const (
+	// ProtocolGraphQLWS is the legacy apollographql/subscriptions-transport-ws
+	// subprotocol. It is the default, for backward compatibility.
+	ProtocolGraphQLWS = "graphql-ws"
+	// ProtocolGraphQLTransportWS is the newer graphql-ws/graphql-transport-ws
+	// subprotocol, used by Hasura, gqlgen and Apollo Server 3+.
+	ProtocolGraphQLTransportWS = "graphql-transport-ws"
+)
+
 const (
 	gqp_init                  subscriptionMessageType = "connection_init"
 	gql_start                                         = "start"
@@ -352,6 +691,13 @@ const (
 	gql_error                                         = "error"
 	gql_complete                                      = "GQL_COMPLETE"
 	gql_connection_keep_alive                         = "ka"
+
+	// graphql-transport-ws message types.
+	gqlt_subscribe subscriptionMessageType = "subscribe"
+	gqlt_next                              = "next"
+	gqlt_complete                          = "complete"
+	gqlt_ping                              = "ping"
+	gqlt_pong                              = "pong"
 )
 
 type subscriptionMessage struct {
@@ -360,60 +706,361 @@ type subscriptionMessage struct {
 	Type    subscriptionMessageType `json:"type"`
 }
 
-func (c * Client) SubscriptionClient(ctx context.Context, header http.Header) (* SubscriptionClient, error) {
-	dialer := websocket.DefaultDialer
-	header.Set("Sec-WebSocket-Protocol", "graphql-ws")
-	header.Set("Content-Type", "application/json")
+// WithSubscriptionProtocol selects the websocket subprotocol used by
+// SubscriptionClient. Supported values are ProtocolGraphQLWS (the
+// default, legacy protocol) and ProtocolGraphQLTransportWS.
+func WithSubscriptionProtocol(protocol string) ClientOption {
+	return func(client *Client) {
+		client.subscriptionProtocol = protocol
+	}
+}
+
+// WithSubscriptionReconnect configures the exponential backoff (with
+// jitter) used by SubscriptionClient when the connection is lost. A
+// transient disconnect never closes subscriptions returned by Subscribe;
+// it is retried with delays growing from min to max.
+func WithSubscriptionReconnect(min, max time.Duration) ClientOption {
+	return func(client *Client) {
+		client.subscriptionMinBackoff = min
+		client.subscriptionMaxBackoff = max
+	}
+}
+
+// WithSubscriptionKeepaliveTimeout closes (and reconnects) the
+// subscription connection if no message at all, including server
+// keepalives, is received within d.
+func WithSubscriptionKeepaliveTimeout(d time.Duration) ClientOption {
+	return func(client *Client) {
+		client.subscriptionKeepaliveTimeout = d
+	}
+}
+
+// WithWebsocketDialer overrides the websocket.Dialer used to open
+// subscription connections, e.g. to set a TLSClientConfig, a proxy, or
+// handshake timeouts. Defaults to websocket.DefaultDialer.
+func WithWebsocketDialer(dialer *websocket.Dialer) ClientOption {
+	return func(client *Client) {
+		client.subscriptionDialer = dialer
+	}
+}
+
+// WithKeepAlivePing sets the interval at which a subscription
+// connection speaking the graphql-transport-ws protocol sends a client
+// ping to keep the connection (and any intermediate proxy) alive.
+// Defaults to 20 seconds; it has no effect on the legacy graphql-ws
+// protocol, which relies on server-sent keepalives instead (see
+// WithSubscriptionKeepaliveTimeout).
+func WithKeepAlivePing(interval time.Duration) ClientOption {
+	return func(client *Client) {
+		client.subscriptionPingInterval = interval
+	}
+}
+
+// WithConnectionInitPayload sets the payload marshaled and sent
+// alongside connection_init when opening a subscription connection,
+// e.g. an auth token the server expects before acknowledging the
+// connection.
+func WithConnectionInitPayload(payload interface{}) ClientOption {
+	return func(client *Client) {
+		client.subscriptionInitPayload = payload
+	}
+}
+
+func (c *Client) SubscriptionClient(ctx context.Context, header http.Header) (*SubscriptionClient, error) {
+	protocol := c.subscriptionProtocol
+	if protocol == "" {
+		protocol = ProtocolGraphQLWS
+	}
+	return c.newSubscriptionClient(ctx, header, protocol)
+}
+
+// newSubscriptionClient dials a SubscriptionClient using protocol,
+// regardless of c.subscriptionProtocol. It is shared by SubscriptionClient,
+// which defaults to the legacy protocol, and Subscribe, which always
+// speaks graphql-transport-ws.
+func (c *Client) newSubscriptionClient(ctx context.Context, header http.Header, protocol string) (*SubscriptionClient, error) {
+	minBackoff := c.subscriptionMinBackoff
+	if minBackoff <= 0 {
+		minBackoff = 500 * time.Millisecond
+	}
+	maxBackoff := c.subscriptionMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
 
-	conn, _, err := dialer.DialContext(ctx, strings.Replace(c.endpoint, "http", "ws", 1), header)
+	// replay cookies from a jar installed by WithCookieJar on the
+	// websocket handshake, as Run does for HTTP requests.
+	dialer := c.subscriptionDialer
+	if c.httpClient != nil && c.httpClient.Jar != nil && (dialer == nil || dialer.Jar == nil) {
+		withJar := *websocket.DefaultDialer
+		if dialer != nil {
+			withJar = *dialer
+		}
+		withJar.Jar = c.httpClient.Jar
+		dialer = &withJar
+	}
+
+	subClient := &SubscriptionClient{
+		subBuffer:        make(chan subscriptionMessage),
+		protocol:         protocol,
+		dialer:           dialer,
+		initPayload:      c.subscriptionInitPayload,
+		ctx:              ctx,
+		header:           header,
+		endpoint:         c.endpoint,
+		minBackoff:       minBackoff,
+		maxBackoff:       maxBackoff,
+		keepaliveTimeout: c.subscriptionKeepaliveTimeout,
+		pingInterval:     c.subscriptionPingInterval,
+		closing:          make(chan struct{}),
+		errCh:            make(chan error, 1),
+	}
 
+	conn, ack, err := subClient.dial()
+	if err != nil {
+		return nil, err
+	}
+	subClient.subWebsocket = conn
+	subClient.connAckPayload = ack
+
+	go subClient.superviseLoop()
+	return subClient, nil
+}
+
+// dial opens a new websocket connection and waits for the server's
+// connection_ack, returning its payload. A connection_init message is
+// sent first only for graphql-transport-ws; with the legacy graphql-ws
+// protocol no connection_init is sent, on the first dial or on a
+// reconnect.
+func (c *SubscriptionClient) dial() (*websocket.Conn, *json.RawMessage, error) {
+	dialer := c.dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	header := c.header.Clone()
+	header.Set("Sec-WebSocket-Protocol", c.protocol)
+	header.Set("Content-Type", "application/json")
+
+	conn, _, err := dialer.DialContext(c.ctx, strings.Replace(c.endpoint, "http", "ws", 1), header)
 	if err != nil {
 		if conn != nil {
 			_ = conn.Close()
 		}
-		return nil, err
+		return nil, nil, err
 	}
-	subClient := &SubscriptionClient{
-		subWebsocket: conn,
-		subBuffer: make(chan subscriptionMessage),
+
+	if c.protocol == ProtocolGraphQLTransportWS {
+		var payload *json.RawMessage
+		if c.initPayload != nil {
+			b, err := json.Marshal(c.initPayload)
+			if err != nil {
+				conn.Close()
+				return nil, nil, errors.Wrap(err, "encode connection_init payload")
+			}
+			raw := json.RawMessage(b)
+			payload = &raw
+		}
+		if err := conn.WriteJSON(subscriptionMessage{Type: gqp_init, Payload: payload}); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
 	}
 
 	var msg subscriptionMessage
-	err = conn.ReadJSON(&msg)
-	if err != nil {
-		return nil, err
+	if err := conn.ReadJSON(&msg); err != nil {
+		conn.Close()
+		return nil, nil, err
 	}
 
 	if msg.Type != gql_connection_ack {
 		conn.Close()
 		if msg.Type == gql_connection_error {
 			errJ, _ := json.Marshal(*msg.Payload)
-			return nil, errors.New(string(errJ))
-		} else {
-			return nil, errors.New("server-did-not-acknowledge")
+			return nil, nil, errors.New(string(errJ))
 		}
+		return nil, nil, errors.New("server-did-not-acknowledge")
 	}
+	return conn, msg.Payload, nil
+}
 
+// currentConn returns the websocket connection currently in use.
+func (c *SubscriptionClient) currentConn() *websocket.Conn {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.subWebsocket
+}
 
-	go subClient.subWork()
-	return subClient, nil
+// writeJSON serializes msg to the current connection, serializing writers
+// since gorilla/websocket does not allow concurrent writes.
+func (c *SubscriptionClient) writeJSON(msg subscriptionMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.currentConn().WriteJSON(msg)
+}
+
+func (c *SubscriptionClient) isClosing() bool {
+	select {
+	case <-c.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// pingLoop sends periodic keepalive pings on conn when using the
+// graphql-transport-ws protocol. It returns at the first tick after conn
+// has been replaced by a reconnect or the client is closed, or once a
+// write fails.
+func (c *SubscriptionClient) pingLoop(conn *websocket.Conn) {
+	interval := c.pingInterval
+	if interval <= 0 {
+		interval = 20 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if c.isClosing() || c.currentConn() != conn {
+			return
+		}
+		if err := c.writeJSON(subscriptionMessage{Type: gqlt_ping}); err != nil {
+			return
+		}
+	}
+}
+
+// superviseLoop runs the read loop for the current connection and, on
+// transient failure, reconnects with backoff and resubscribes every live
+// Subscription before resuming. It returns once the client has been
+// permanently closed or the context has been canceled.
+func (c *SubscriptionClient) superviseLoop() {
+	for {
+		if c.protocol == ProtocolGraphQLTransportWS {
+			go c.pingLoop(c.currentConn())
+		}
+
+		err := c.subWork()
+		if err == nil {
+			// permanent shutdown (Close was called)
+			return
+		}
+
+		select {
+		case c.errCh <- err:
+		default:
+		}
+
+		if c.isClosing() {
+			return
+		}
+		select {
+		case <-c.ctx.Done():
+			c.closeAllSubs()
+			return
+		default:
+		}
+
+		conn, ack, err := c.reconnectWithBackoff()
+		if err != nil {
+			// ctx canceled or client closed while reconnecting
+			c.closeAllSubs()
+			return
+		}
+
+		c.connMu.Lock()
+		c.subWebsocket = conn
+		c.connAckPayload = ack
+		c.connMu.Unlock()
+
+		c.resubscribeAll()
+	}
+}
+
+// reconnectWithBackoff redials until it succeeds or the client is closed
+// or its context is canceled.
+func (c *SubscriptionClient) reconnectWithBackoff() (*websocket.Conn, *json.RawMessage, error) {
+	backoff := c.minBackoff
+	for {
+		select {
+		case <-c.closing:
+			return nil, nil, errors.New("subscription client closed")
+		case <-c.ctx.Done():
+			return nil, nil, c.ctx.Err()
+		case <-time.After(jitter(backoff)):
+		}
+
+		conn, ack, err := c.dial()
+		if err == nil {
+			return conn, ack, nil
+		}
+
+		select {
+		case c.errCh <- err:
+		default:
+		}
+
+		backoff *= 2
+		if backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// jitter returns a random duration between d/2 and d.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	half := int64(d) / 2
+	return time.Duration(half + rand.Int63n(half+1))
 }
 
-func (c * SubscriptionClient) Close() error {
-	if c.subWebsocket == nil {
+// resubscribeAll re-sends the subscribe/start message for every live
+// Subscription, so callers see no interruption after a reconnect.
+func (c *SubscriptionClient) resubscribeAll() {
+	c.subs.Range(func(key, value interface{}) bool {
+		id := key.(string)
+		entry := value.(*subscriptionEntry)
+		_ = c.sendSubscribe(id, entry.req)
+		return true
+	})
+}
+
+// closeAllSubs closes every live Subscription channel. It is only called
+// on permanent shutdown: an explicit Close, context cancellation, or
+// giving up on reconnection.
+func (c *SubscriptionClient) closeAllSubs() {
+	c.subs.Range(func(_, value interface{}) bool {
+		close(value.(*subscriptionEntry).ch)
+		return true
+	})
+}
+
+func (c *SubscriptionClient) Close() error {
+	conn := c.currentConn()
+	if conn == nil {
 		return nil
 	}
-	err := c.subWebsocket.WriteJSON(subscriptionMessage{Type: gql_connection_terminate})
-	if err != nil {
-		return err
+	c.closeOnce.Do(func() {
+		close(c.closing)
+	})
+	// graphql-transport-ws has no connection_terminate message; the client
+	// simply closes the socket. Best-effort: a write failure here doesn't
+	// stop us from closing the connection below.
+	var writeErr error
+	if c.protocol != ProtocolGraphQLTransportWS {
+		writeErr = c.writeJSON(subscriptionMessage{Type: gql_connection_terminate})
 	}
 
+	// subWork's read loop blocks on conn.ReadJSON, which on an otherwise
+	// idle connection only unblocks once the socket is actually closed.
+	// Close it before waiting on subWait, or Close would deadlock.
+	closeErr := conn.Close()
 	c.subWait.Wait()
-	err = c.subWebsocket.Close()
-	if err != nil {
-		return err
+
+	if writeErr != nil {
+		return writeErr
 	}
-	return nil
+	return closeErr
 }
 
 type SubscriptionPayload struct {
@@ -423,53 +1070,80 @@ type SubscriptionPayload struct {
 
 type Subscription chan SubscriptionPayload
 
-func (c * SubscriptionClient) subWork() {
+// subWork reads messages from the current connection until it fails or
+// the client is closed. It returns nil only on a permanent, intentional
+// shutdown; any other return value is a transient error that the caller
+// should retry after reconnecting.
+func (c *SubscriptionClient) subWork() error {
 	c.subWait.Add(1)
 	defer c.subWait.Done()
-	defer c.subs.Range(func (_, sub interface{}) bool {
-			close(sub.(Subscription))
-			return true
-		})
 
+	conn := c.currentConn()
 	for {
-		var msg subscriptionMessage
-		err := c.subWebsocket.ReadJSON(&msg)
+		if c.keepaliveTimeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(c.keepaliveTimeout))
+		}
 
+		var msg subscriptionMessage
+		err := conn.ReadJSON(&msg)
 		if err != nil {
-			if err == io.ErrUnexpectedEOF || err == io.EOF {
-				//close every subscription
-				return
-			}
-			if strings.HasSuffix(err.Error(), io.ErrUnexpectedEOF.Error()) {
-				return
+			if c.isClosing() {
+				c.closeAllSubs()
+				return nil
 			}
-
-			log.Fatalf("Error reading from subscription websocket : %s",  err)
-			return
+			return err
 		}
 
 		switch msg.Type {
 		case gql_error:
 			id := *msg.Id
-			ch, _ := c.subs.Load(id)
-			ch.(Subscription) <- SubscriptionPayload{Error: msg.Payload}
-		case gql_data:
+			entry, ok := c.subs.Load(id)
+			if !ok {
+				continue
+			}
+			entry.(*subscriptionEntry).ch <- SubscriptionPayload{Error: msg.Payload}
+		case gql_data, gqlt_next:
 			id := *msg.Id
-			ch, _ := c.subs.Load(id)
-			ch.(Subscription) <- SubscriptionPayload{Data: msg.Payload}
-		case gql_complete:
+			entry, ok := c.subs.Load(id)
+			if !ok {
+				continue
+			}
+			entry.(*subscriptionEntry).ch <- SubscriptionPayload{Data: msg.Payload}
+		case gql_complete, gqlt_complete:
 			id := *msg.Id
-			ch, _ := c.subs.Load(id)
-			close(ch.(Subscription))
+			entry, ok := c.subs.Load(id)
+			if !ok {
+				continue
+			}
+			close(entry.(*subscriptionEntry).ch)
 			c.subs.Delete(id)
-
-		case gql_connection_keep_alive://ignore...
+		case gqlt_ping:
+			_ = c.writeJSON(subscriptionMessage{Type: gqlt_pong})
+		case gqlt_pong:
+			// reply to our keepalive ping, nothing to do
+		case gql_connection_keep_alive: //ignore...
 		}
 	}
 }
 
 func (c * SubscriptionClient) Subscribe(req * Request) (Subscription, error) {
+	id := strconv.Itoa(c.subIdGen)
+	c.subIdGen++
 
+	subChan := make(Subscription)
+	c.subs.Store(id, &subscriptionEntry{req: req, ch: subChan})
+	if err := c.sendSubscribe(id, req); err != nil {
+		c.subs.Delete(id)
+		return nil, err
+	}
+
+	return subChan, nil
+}
+
+// sendSubscribe writes the subscribe/start message for id and req to the
+// current connection. It is used both by Subscribe and, on reconnect, to
+// resume every live Subscription.
+func (c *SubscriptionClient) sendSubscribe(id string, req *Request) error {
 	var requestBody bytes.Buffer
 	requestBodyObj := struct {
 		Query     string                 `json:"query"`
@@ -479,36 +1153,111 @@ func (c * SubscriptionClient) Subscribe(req * Request) (Subscription, error) {
 		Variables: req.vars,
 	}
 	if err := json.NewEncoder(&requestBody).Encode(requestBodyObj); err != nil {
-		return nil, errors.Wrap(err, "encode body")
+		return errors.Wrap(err, "encode body")
 	}
 
-	id := strconv.Itoa(c.subIdGen)
-	c.subIdGen ++
-
+	startType := subscriptionMessageType(gql_start)
+	if c.protocol == ProtocolGraphQLTransportWS {
+		startType = gqlt_subscribe
+	}
 	payload := json.RawMessage(requestBody.Bytes())
-	sReq := subscriptionMessage{
+	id2 := id
+	return c.writeJSON(subscriptionMessage{
 		Payload: &payload,
-		Id:      &id,
-		Type:    gql_start,
-	}
-
-	subChan := make(Subscription)
-	c.subs.Store(id, subChan)
-	err := c.subWebsocket.WriteJSON(sReq)
-	if err != nil {
-		return nil, err
-	}
-
-	return subChan, nil
+		Id:      &id2,
+		Type:    startType,
+	})
 }
 
 func (c * SubscriptionClient) Unsubscribe(sub Subscription)  {
+	stopType := subscriptionMessageType(gql_stop)
+	if c.protocol == ProtocolGraphQLTransportWS {
+		stopType = gqlt_complete
+	}
 	c.subs.Range(func(key interface{}, value interface {}) bool {
-		if value == sub {
+		if value.(*subscriptionEntry).ch == sub {
 			id := key.(string)
-			_ = c.subWebsocket.WriteJSON(subscriptionMessage{Id: &id, Type: gql_stop})
+			_ = c.writeJSON(subscriptionMessage{Id: &id, Type: stopType})
+			c.subs.Delete(id)
 			return false
 		}
 		return true
 	})
 }
+
+// ActiveSubscription is a handle to a single live GraphQL subscription
+// opened by Client.Subscribe. It owns the underlying websocket
+// connection, so Close it once the subscription is no longer needed.
+type ActiveSubscription struct {
+	sc  *SubscriptionClient
+	sub Subscription
+}
+
+// Subscribe opens a websocket to the Client's endpoint and speaks the
+// graphql-transport-ws protocol: connection_init (with the payload set
+// by WithConnectionInitPayload, if any), wait for connection_ack, then
+// subscribe req. It is a convenience over SubscriptionClient for callers
+// that only need a single subscription per connection; for several
+// subscriptions sharing one connection, use Client.SubscriptionClient
+// directly.
+func (c *Client) Subscribe(ctx context.Context, req *Request) (*ActiveSubscription, error) {
+	sc, err := c.newSubscriptionClient(ctx, req.Header, ProtocolGraphQLTransportWS)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := sc.Subscribe(req)
+	if err != nil {
+		_ = sc.Close()
+		return nil, err
+	}
+	return &ActiveSubscription{sc: sc, sub: sub}, nil
+}
+
+// Next blocks until the next payload arrives, the subscription
+// completes, or ctx is done. It returns a nil error and a nil
+// RawMessage once the subscription has terminated normally (a complete
+// message, or the connection closing after Close).
+func (s *ActiveSubscription) Next(ctx context.Context) (json.RawMessage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case payload, ok := <-s.sub:
+		if !ok {
+			return nil, nil
+		}
+		if payload.Error != nil {
+			var errs Errors
+			if err := json.Unmarshal(*payload.Error, &errs); err != nil {
+				return nil, errors.Wrap(err, "decoding subscription error")
+			}
+			return nil, errs
+		}
+		if payload.Data == nil {
+			return nil, nil
+		}
+		return json.RawMessage(*payload.Data), nil
+	}
+}
+
+// NextInto is a convenience over Next for callers who want each
+// subscription payload decoded straight into dest, rather than handling
+// the raw json.RawMessage themselves. It blocks, decodes and returns
+// exactly as Next does, except a normal end of subscription is reported
+// as io.EOF instead of a nil error, since there is no payload left to
+// leave dest unmodified.
+func (s *ActiveSubscription) NextInto(ctx context.Context, dest interface{}) error {
+	payload, err := s.Next(ctx)
+	if err != nil {
+		return err
+	}
+	if payload == nil {
+		return io.EOF
+	}
+	return json.Unmarshal(payload, dest)
+}
+
+// Close unsubscribes and tears down the underlying websocket connection.
+func (s *ActiveSubscription) Close() error {
+	s.sc.Unsubscribe(s.sub)
+	return s.sc.Close()
+}
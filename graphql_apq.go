@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// errPersistedQueryNotFound is the message APQ-compatible servers (Apollo
+// Router, Hasura, ...) use when they haven't seen the hash before.
+const errPersistedQueryNotFound = "PersistedQueryNotFound"
+
+// PersistedQueryHash returns the hex-encoded SHA-256 hash of the query,
+// computing and caching it on first use.
+func (req *Request) PersistedQueryHash() string {
+	if req.persistedQueryHash == "" {
+		sum := sha256.Sum256([]byte(req.q))
+		req.persistedQueryHash = hex.EncodeToString(sum[:])
+	}
+	return req.persistedQueryHash
+}
+
+// PersistedQueryCache stores the persisted query hash computed for a
+// query's text, so it can be shared across requests and, if a caller
+// supplies their own implementation, across Clients. Callers can also
+// pre-populate it with a query's hash so it is never computed; this does
+// not skip the registering round trip, which happens whenever the server
+// replies PersistedQueryNotFound. See WithPersistedQueryCache.
+type PersistedQueryCache interface {
+	// Hash returns the cached hash for query, and whether it was found.
+	Hash(query string) (hash string, ok bool)
+	// Store records hash as the result of hashing query.
+	Store(query, hash string)
+}
+
+// memPersistedQueryCache is the default PersistedQueryCache: an
+// unbounded, in-memory cache private to the Client that created it.
+type memPersistedQueryCache struct {
+	hashes sync.Map // query (string) -> hash (string)
+}
+
+func newMemPersistedQueryCache() *memPersistedQueryCache {
+	return &memPersistedQueryCache{}
+}
+
+func (c *memPersistedQueryCache) Hash(query string) (string, bool) {
+	v, ok := c.hashes.Load(query)
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+func (c *memPersistedQueryCache) Store(query, hash string) {
+	c.hashes.Store(query, hash)
+}
+
+// hashForRequest returns the persisted query hash for req, consulting
+// c.persistedQueryCache before falling back to computing (and caching)
+// it via req.PersistedQueryHash.
+func (c *Client) hashForRequest(req *Request) string {
+	if hash, ok := c.persistedQueryCache.Hash(req.q); ok {
+		return hash
+	}
+	hash := req.PersistedQueryHash()
+	c.persistedQueryCache.Store(req.q, hash)
+	return hash
+}
+
+type persistedQueryExtensions struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// runWithPersistedQuery implements Automatic Persisted Queries: it sends
+// only the query's hash on the first attempt, and on a
+// PersistedQueryNotFound response, retries once with the full query
+// alongside the hash, so the server can register it for next time. By
+// default both attempts are POSTs; WithAPQOverGET sends them as GETs
+// instead, so a CDN in front of the server can cache the response — but
+// only for GET-safe requests (queries and subscriptions): mutations
+// always go over POST, since the GraphQL-over-HTTP GET contract requires
+// the request be side-effect-free, and sending a mutation as a GET is a
+// CSRF vector against servers that don't enforce that themselves. Each
+// attempt is routed through the Client's interceptor chain, exactly
+// like runWithJSON and runWithPostFields, so WithBearerToken and
+// WithRetry apply here too.
+func (c *Client) runWithPersistedQuery(ctx context.Context, req *Request, resp interface{}) error {
+	hash := c.hashForRequest(req)
+	if c.apqOverGET {
+		if _, opType := req.OperationNameAndType(); opType != "mutation" {
+			return c.runPersistedQueryGET(ctx, req, resp, hash)
+		}
+	}
+	return c.runPersistedQueryPOST(ctx, req, resp, hash)
+}
+
+// isPersistedQueryNotFound reports whether r is an APQ server's
+// PersistedQueryNotFound response, asking the caller to retry with the
+// full query rather than a real error to report back.
+func isPersistedQueryNotFound(r *Response) bool {
+	return len(r.Errors) > 0 && r.Errors[0].Message == errPersistedQueryNotFound
+}
+
+// runPersistedQueryPOST performs the POST-transport APQ round trip,
+// retrying once with the full query on a PersistedQueryNotFound
+// response.
+func (c *Client) runPersistedQueryPOST(ctx context.Context, req *Request, resp interface{}, hash string) error {
+	result, err := c.chainInterceptors(c.persistedQueryPOSTRoundTrip(hash, false))(ctx, req)
+	if err != nil {
+		return err
+	}
+	if isPersistedQueryNotFound(result) {
+		result, err = c.chainInterceptors(c.persistedQueryPOSTRoundTrip(hash, true))(ctx, req)
+		if err != nil {
+			return err
+		}
+	}
+	return decodeResponse(result, resp)
+}
+
+// persistedQueryPOSTRoundTrip returns the terminal RoundTrip for a
+// single POST-transport APQ attempt: includeQuery controls whether the
+// full query text is sent alongside the hash, or just the hash.
+func (c *Client) persistedQueryPOSTRoundTrip(hash string, includeQuery bool) RoundTrip {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		body := struct {
+			Query      string                 `json:"query,omitempty"`
+			Variables  map[string]interface{} `json:"variables,omitempty"`
+			Extensions map[string]interface{} `json:"extensions"`
+		}{
+			Variables: req.vars,
+			Extensions: map[string]interface{}{
+				"persistedQuery": persistedQueryExtensions{Version: 1, Sha256Hash: hash},
+			},
+		}
+		if includeQuery {
+			body.Query = req.q
+		}
+		var requestBody bytes.Buffer
+		if err := json.NewEncoder(&requestBody).Encode(body); err != nil {
+			return nil, errors.Wrap(err, "encode body")
+		}
+		c.logf(">> variables: %v", req.vars)
+		if includeQuery {
+			c.logf(">> query: %s", req.q)
+		}
+
+		r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		r.Header.Set("Accept", "application/json; charset=utf-8")
+		for key, values := range req.Header {
+			for _, value := range values {
+				r.Header.Add(key, value)
+			}
+		}
+		c.logf(">> headers: %v", r.Header)
+		return c.doHTTP(ctx, r)
+	}
+}
+
+// runPersistedQueryGET performs the GET-transport APQ round trip,
+// retrying once with the full query on a PersistedQueryNotFound
+// response. See WithAPQOverGET.
+func (c *Client) runPersistedQueryGET(ctx context.Context, req *Request, resp interface{}, hash string) error {
+	result, err := c.chainInterceptors(c.persistedQueryGETRoundTrip(hash, false))(ctx, req)
+	if err != nil {
+		return err
+	}
+	if isPersistedQueryNotFound(result) {
+		result, err = c.chainInterceptors(c.persistedQueryGETRoundTrip(hash, true))(ctx, req)
+		if err != nil {
+			return err
+		}
+	}
+	return decodeResponse(result, resp)
+}
+
+// persistedQueryGETRoundTrip returns the terminal RoundTrip for a single
+// GET-transport APQ attempt: includeQuery controls whether the full
+// query text is sent alongside the hash, or just the hash.
+func (c *Client) persistedQueryGETRoundTrip(hash string, includeQuery bool) RoundTrip {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		extensions := map[string]interface{}{
+			"persistedQuery": persistedQueryExtensions{Version: 1, Sha256Hash: hash},
+		}
+		extensionsJSON, err := json.Marshal(extensions)
+		if err != nil {
+			return nil, errors.Wrap(err, "encode extensions")
+		}
+		variablesJSON, err := json.Marshal(req.vars)
+		if err != nil {
+			return nil, errors.Wrap(err, "encode variables")
+		}
+
+		values := url.Values{}
+		values.Set("extensions", string(extensionsJSON))
+		if len(req.vars) > 0 {
+			values.Set("variables", string(variablesJSON))
+		}
+		if includeQuery {
+			values.Set("query", req.q)
+		}
+
+		r, err := http.NewRequest(http.MethodGet, c.endpoint+"?"+values.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Accept", "application/json; charset=utf-8")
+		for key, vals := range req.Header {
+			for _, value := range vals {
+				r.Header.Add(key, value)
+			}
+		}
+		c.logf(">> GET %s", r.URL.String())
+		return c.doHTTP(ctx, r)
+	}
+}
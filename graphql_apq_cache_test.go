@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPersistedQueryCacheShared(t *testing.T) {
+	var gets int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets++
+		_, _ = w.Write([]byte(`{"data":{"value":"hit"}}`))
+	}))
+	defer srv.Close()
+
+	cache := newMemPersistedQueryCache()
+	client1 := NewClient(srv.URL, WithPersistedQueryCache(cache))
+	client2 := NewClient(srv.URL, WithPersistedQueryCache(cache))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	var resp struct{ Value string }
+	assert.NoError(t, client1.Run(ctx, NewRequest("query {}"), &resp))
+	assert.Equal(t, 1, gets)
+
+	hash, ok := cache.Hash("query {}")
+	assert.True(t, ok)
+	assert.NotEmpty(t, hash)
+
+	// client2 shares the cache, so its lookup hits without recomputing.
+	gotHash, ok := client2.persistedQueryCache.Hash("query {}")
+	assert.True(t, ok)
+	assert.Equal(t, hash, gotHash)
+}
+
+func TestWithPersistedQueryCachePreregistered(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Empty(t, body.Query, "the first attempt should send only the pre-registered hash")
+		_, _ = w.Write([]byte(`{"data":{"value":"hit"}}`))
+	}))
+	defer srv.Close()
+
+	req := NewRequest("query {}")
+	cache := newMemPersistedQueryCache()
+	cache.Store(req.Query(), req.PersistedQueryHash())
+
+	client := NewClient(srv.URL, WithPersistedQueryCache(cache))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	var resp struct{ Value string }
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp))
+	assert.Equal(t, "hit", resp.Value)
+}
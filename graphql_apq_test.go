@@ -0,0 +1,164 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistedQueryColdMissThenHit(t *testing.T) {
+	var calls int
+	var registeredQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, http.MethodPost, r.Method)
+		raw, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var body struct {
+			Query      string                 `json:"query"`
+			Extensions map[string]interface{} `json:"extensions"`
+		}
+		assert.NoError(t, json.Unmarshal(raw, &body))
+		extensions, err := json.Marshal(body.Extensions)
+		assert.NoError(t, err)
+		assert.Contains(t, string(extensions), `"sha256Hash"`)
+
+		if body.Query == "" {
+			if registeredQuery == "" {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"value":"cache hit"}}`))
+			return
+		}
+		registeredQuery = body.Query
+		_, _ = w.Write([]byte(`{"data":{"value":"registered"}}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, WithPersistedQueries())
+
+	req := NewRequest("query {}")
+	var resp1 struct{ Value string }
+	assert.NoError(t, client.Run(ctx, req, &resp1))
+	assert.Equal(t, "registered", resp1.Value)
+	assert.Equal(t, 2, calls) // miss + register
+
+	var resp2 struct{ Value string }
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp2))
+	assert.Equal(t, "cache hit", resp2.Value)
+	assert.Equal(t, 3, calls) // straight hit, no retry
+}
+
+// TestPersistedQueryAppliesInterceptors verifies that WithBearerToken and
+// WithRetry, both implemented as RequestInterceptors, still apply once
+// WithPersistedQueries is enabled.
+func TestPersistedQueryAppliesInterceptors(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		if calls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"value":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL,
+		WithPersistedQueries(),
+		WithBearerToken(func(ctx context.Context) (string, time.Time, error) {
+			return "tok", time.Time{}, nil
+		}),
+		WithRetry(3, time.Millisecond),
+	)
+
+	var resp struct{ Value string }
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp))
+	assert.Equal(t, "ok", resp.Value)
+	assert.Equal(t, 2, calls) // one 503, then a retry that succeeds
+}
+
+func TestWithAPQOverGETColdMissThenHit(t *testing.T) {
+	var calls int
+	var registeredQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, http.MethodGet, r.Method)
+		extensions := r.URL.Query().Get("extensions")
+		assert.Contains(t, extensions, `"sha256Hash"`)
+
+		query := r.URL.Query().Get("query")
+		if query == "" {
+			if registeredQuery == "" {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"value":"cache hit"}}`))
+			return
+		}
+		registeredQuery = query
+		_, _ = w.Write([]byte(`{"data":{"value":"registered"}}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, WithAPQOverGET())
+
+	req := NewRequest("query {}")
+	var resp1 struct{ Value string }
+	assert.NoError(t, client.Run(ctx, req, &resp1))
+	assert.Equal(t, "registered", resp1.Value)
+	assert.Equal(t, 2, calls) // miss + register
+
+	var resp2 struct{ Value string }
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp2))
+	assert.Equal(t, "cache hit", resp2.Value)
+	assert.Equal(t, 3, calls) // straight hit, no retry
+}
+
+// TestWithAPQOverGETSendsMutationsAsPOST verifies that WithAPQOverGET
+// never sends a mutation as a GET, since GET must be side-effect-free:
+// mutations always go over POST instead.
+func TestWithAPQOverGETSendsMutationsAsPOST(t *testing.T) {
+	var registered bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var body struct {
+			Query string `json:"query"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body.Query == "" {
+			if !registered {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"value":"hit"}}`))
+			return
+		}
+		registered = true
+		_, _ = w.Write([]byte(`{"data":{"value":"registered"}}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL, WithAPQOverGET())
+
+	var resp struct{ Value string }
+	err := client.Run(ctx, NewRequest("mutation { createUser }"), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "registered", resp.Value)
+}
@@ -0,0 +1,77 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WithCookieJar installs jar on the Client's underlying http.Client, so
+// cookies set by the GraphQL endpoint (e.g. a session cookie returned by
+// a login mutation) are replayed on every subsequent Run and
+// subscription call. If no http.Client has been configured yet, a
+// default one is created. WithCookieJar and WithHTTPClient compose by
+// option order like any other ClientOption: pass WithCookieJar after
+// WithHTTPClient so the jar ends up on your client, not one this option
+// creates for you.
+func WithCookieJar(jar http.CookieJar) ClientOption {
+	return func(client *Client) {
+		if client.httpClient == nil {
+			client.httpClient = &http.Client{}
+		}
+		client.httpClient.Jar = jar
+	}
+}
+
+// BearerTokenFunc returns the bearer token to attach to outgoing
+// requests, and the time it expires. A zero expiresAt means the token
+// does not expire, so fetch is called only once.
+type BearerTokenFunc func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// WithBearerToken sets an "Authorization: Bearer <token>" header on
+// every request, fetching the token lazily on first use and caching it
+// until the expiry fetch reports, at which point it is fetched again.
+// It is implemented as a RequestInterceptor, so it composes with
+// WithInterceptors and works in both JSON and multipart mode.
+func WithBearerToken(fetch BearerTokenFunc) ClientOption {
+	return func(client *Client) {
+		cache := &bearerTokenCache{fetch: fetch}
+		client.interceptors = append(client.interceptors, cache.intercept)
+	}
+}
+
+// bearerTokenCache holds the most recently fetched bearer token, shared
+// across every request the owning Client makes.
+type bearerTokenCache struct {
+	fetch BearerTokenFunc
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (c *bearerTokenCache) intercept(ctx context.Context, req *Request, next RoundTrip) (*Response, error) {
+	token, err := c.get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch bearer token")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return next(ctx, req)
+}
+
+func (c *bearerTokenCache) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && (c.expiresAt.IsZero() || time.Now().Before(c.expiresAt)) {
+		return c.token, nil
+	}
+	token, expiresAt, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expiresAt = token, expiresAt
+	return token, nil
+}
@@ -0,0 +1,80 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCookieJar(t *testing.T) {
+	var sawCookie string
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123"})
+		} else if c, err := r.Cookie("session"); err == nil {
+			sawCookie = c.Value
+		}
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	jar, err := cookiejar.New(nil)
+	assert.NoError(t, err)
+	client := NewClient(srv.URL, WithCookieJar(jar))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.Equal(t, "abc123", sawCookie)
+}
+
+func TestWithBearerToken(t *testing.T) {
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	var fetches int
+	fetch := func(ctx context.Context) (string, time.Time, error) {
+		fetches++
+		return "tok-fresh", time.Now().Add(time.Hour), nil
+	}
+	client := NewClient(srv.URL, WithBearerToken(fetch))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.Equal(t, []string{"Bearer tok-fresh", "Bearer tok-fresh"}, gotAuth)
+	assert.Equal(t, 1, fetches, "a non-expired token should not be re-fetched")
+}
+
+func TestWithBearerTokenRefetchesAfterExpiry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	var fetches int
+	fetch := func(ctx context.Context) (string, time.Time, error) {
+		fetches++
+		return "tok", time.Now().Add(-time.Second), nil // already expired
+	}
+	client := NewClient(srv.URL, WithBearerToken(fetch))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.Equal(t, 2, fetches)
+}
@@ -0,0 +1,250 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// batchRequestItem is one element of the JSON array RunBatch POSTs,
+// matching the batch format Apollo Server, express-graphql and most
+// gateways accept.
+type batchRequestItem struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// BatchError is returned by RunBatch when at least one request in the
+// batch failed. It has one slot per request, in request order; a nil
+// slot means that request succeeded and its response was decoded into
+// the matching resps[i].
+type BatchError []error
+
+func (e BatchError) Error() string {
+	var failed int
+	var first error
+	for _, err := range e {
+		if err == nil {
+			continue
+		}
+		failed++
+		if first == nil {
+			first = err
+		}
+	}
+	if first == nil {
+		return "graphql: batch: no failed requests"
+	}
+	return fmt.Sprintf("graphql: batch: %d of %d requests failed, first error: %v", failed, len(e), first)
+}
+
+// RunBatch sends every request in reqs as a single HTTP round trip,
+// serialized as a JSON array of {query,variables} objects, and expects
+// the server to answer with a JSON array of {data,errors} objects in
+// the same order, which it decodes into the matching resps[i]. It
+// shares doRawHTTP with runWithJSON, so logging, per-request headers
+// (taken from reqs[0], since a batch is a single HTTP request) and
+// ImmediatelyCloseReqBody all behave the same way they do for Run.
+//
+// A per-request GraphQL error does not fail the whole batch: RunBatch
+// decodes every response it can, and returns a non-nil error only if at
+// least one request failed. Once the server has answered with one result
+// per request, that error is a BatchError; a failure of the round trip
+// itself (transport error, unparseable body, wrong number of results) is
+// returned as a plain error instead. Callers that want the per-request
+// detail can do:
+//
+//	var berr graphql.BatchError
+//	if errors.As(err, &berr) {
+//	    for i, err := range berr { ... }
+//	}
+//
+// RunBatch requires the JSON transport: it returns an error if the
+// Client was created with UseMultipartForm, since the multipart request
+// spec has no batch format. It also bypasses the per-request
+// interceptor chain (WithInterceptors, WithRetry, WithBearerToken, ...),
+// which wraps a single RoundTrip and has no batch-shaped equivalent.
+func (c *Client) RunBatch(ctx context.Context, reqs []*Request, resps []interface{}) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if c.useMultipartForm {
+		return errors.New("graphql: RunBatch does not support UseMultipartForm")
+	}
+	if len(resps) != len(reqs) {
+		return fmt.Errorf("graphql: RunBatch: got %d requests but %d responses", len(reqs), len(resps))
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	items := make([]batchRequestItem, len(reqs))
+	for i, req := range reqs {
+		items[i] = batchRequestItem{Query: req.q, Variables: req.vars}
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(items); err != nil {
+		return fmt.Errorf("graphql: encode batch body: %w", err)
+	}
+	c.logf(">> batch of %d queries", len(reqs))
+
+	r, err := http.NewRequest(http.MethodPost, c.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("Accept", "application/json; charset=utf-8")
+	for key, values := range reqs[0].Header {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	c.logf(">> headers: %v", r.Header)
+
+	res, raw, err := c.doRawHTTP(ctx, r)
+	if err != nil {
+		return err
+	}
+	var results []envelope
+	if err := json.Unmarshal(raw, &results); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return &httpStatusError{StatusCode: res.StatusCode, RetryAfter: parseRetryAfter(res.Header)}
+		}
+		return fmt.Errorf("graphql: decoding batch response: %w", err)
+	}
+	if len(results) != len(reqs) {
+		return fmt.Errorf("graphql: RunBatch: server returned %d results for %d requests", len(results), len(reqs))
+	}
+
+	berr := make(BatchError, len(reqs))
+	var anyFailed bool
+	for i, result := range results {
+		if resps[i] != nil && len(result.Data) > 0 {
+			if err := json.Unmarshal(result.Data, resps[i]); err != nil {
+				berr[i] = fmt.Errorf("graphql: decoding response: %w", err)
+				anyFailed = true
+				continue
+			}
+		}
+		if len(result.Errors) > 0 {
+			berr[i] = result.Errors
+			anyFailed = true
+		}
+	}
+	if !anyFailed {
+		return nil
+	}
+	return berr
+}
+
+// WithBatchInterval makes the Client transparently coalesce concurrent
+// Run calls into RunBatch calls: each Run joins the batch currently
+// being assembled, which is sent after d elapses since the first call
+// joined it, or once it holds max requests, whichever comes first (max
+// <= 0 means no count limit, only the time one). Existing Run callers
+// need no code changes; each still gets back only its own decoded
+// response and error.
+//
+// The coalesced RunBatch call is made with a background context, since
+// it serves callers whose individual contexts may have different
+// deadlines; an individual Run call still returns promptly if its own
+// ctx is canceled, though the batch it joined keeps going for the
+// others. WithBatchInterval requires the JSON transport, same as
+// RunBatch.
+//
+// Like RunBatch, a batched Run call bypasses the per-request interceptor
+// chain: there is no single RoundTrip for WithInterceptors, WithRetry or
+// WithBearerToken to wrap once several requests share one HTTP call. So
+// that this isn't silently lost, Run returns an error immediately if the
+// Client was built with WithBatchInterval alongside any of them; pick
+// one or the other.
+func WithBatchInterval(d time.Duration, max int) ClientOption {
+	return func(client *Client) {
+		client.batcher = &batcher{client: client, interval: d, max: max}
+	}
+}
+
+// batcher coalesces concurrent Client.Run calls into RunBatch calls.
+// See WithBatchInterval.
+type batcher struct {
+	client   *Client
+	interval time.Duration
+	max      int
+
+	mu      sync.Mutex
+	pending *batchGroup
+}
+
+// batchGroup is one in-flight coalesced batch.
+type batchGroup struct {
+	reqs  []*Request
+	resps []interface{}
+	done  []chan error
+}
+
+func (b *batcher) run(ctx context.Context, req *Request, resp interface{}) error {
+	b.mu.Lock()
+	g := b.pending
+	if g == nil {
+		g = &batchGroup{}
+		b.pending = g
+		time.AfterFunc(b.interval, func() { b.flush(g) })
+	}
+	done := make(chan error, 1)
+	g.reqs = append(g.reqs, req)
+	g.resps = append(g.resps, resp)
+	g.done = append(g.done, done)
+	full := b.max > 0 && len(g.reqs) >= b.max
+	if full {
+		b.pending = nil
+	}
+	b.mu.Unlock()
+
+	if full {
+		go b.send(g)
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush sends g if it is still the pending batch, i.e. it hasn't
+// already been sent early by filling up to max.
+func (b *batcher) flush(g *batchGroup) {
+	b.mu.Lock()
+	stillPending := b.pending == g
+	if stillPending {
+		b.pending = nil
+	}
+	b.mu.Unlock()
+	if stillPending {
+		b.send(g)
+	}
+}
+
+func (b *batcher) send(g *batchGroup) {
+	err := b.client.RunBatch(context.Background(), g.reqs, g.resps)
+	var berr BatchError
+	isBatchErr := errors.As(err, &berr)
+	for i, done := range g.done {
+		switch {
+		case isBatchErr:
+			done <- berr[i]
+		case err != nil:
+			done <- err
+		default:
+			done <- nil
+		}
+	}
+}
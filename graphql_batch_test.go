@@ -0,0 +1,189 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatchPartialFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"data":{"value":1}},
+			{"errors":[{"message":"boom"}]}
+		]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	var r1, r2 struct {
+		Value int `json:"value"`
+	}
+	err := client.RunBatch(context.Background(),
+		[]*Request{NewRequest(`query { a }`), NewRequest(`query { b }`)},
+		[]interface{}{&r1, &r2},
+	)
+	require.Error(t, err)
+
+	var berr BatchError
+	require.True(t, errors.As(err, &berr))
+	assert.NoError(t, berr[0])
+	assert.Error(t, berr[1])
+	assert.Equal(t, 1, r1.Value)
+}
+
+func TestRunBatchAllSuccess(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"data":{"value":1}},{"data":{"value":2}}]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	var r1, r2 struct {
+		Value int `json:"value"`
+	}
+	err := client.RunBatch(context.Background(),
+		[]*Request{NewRequest(`query { a }`), NewRequest(`query { b }`)},
+		[]interface{}{&r1, &r2},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, r1.Value)
+	assert.Equal(t, 2, r2.Value)
+}
+
+func TestRunBatchRejectsMultipartForm(t *testing.T) {
+	client := NewClient("https://example.com/graphql", UseMultipartForm())
+	err := client.RunBatch(context.Background(),
+		[]*Request{NewRequest(`query { a }`)},
+		[]interface{}{&struct{}{}},
+	)
+	assert.Error(t, err)
+}
+
+func TestRunBatchEmpty(t *testing.T) {
+	client := NewClient("https://example.com/graphql")
+	err := client.RunBatch(context.Background(), nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestRunBatchSendsHeadersFromFirstRequest(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`[{"data":{"value":1}}]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	req := NewRequest(`query { a }`)
+	req.Header.Set("Authorization", "Bearer tok")
+	var r1 struct {
+		Value int `json:"value"`
+	}
+	err := client.RunBatch(context.Background(), []*Request{req}, []interface{}{&r1})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer tok", gotAuth)
+}
+
+func TestWithBatchIntervalCoalescesConcurrentRuns(t *testing.T) {
+	var calls int32
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`[{"data":{"value":1}},{"data":{"value":2}},{"data":{"value":3}}]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithBatchInterval(50*time.Millisecond, 0))
+
+	var wg sync.WaitGroup
+	results := make([]struct {
+		Value int `json:"value"`
+	}, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := client.Run(context.Background(), NewRequest(`query { a }`), &results[i])
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestWithBatchIntervalFlushesEarlyAtMax(t *testing.T) {
+	var calls int32
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`[{"data":{"value":1}},{"data":{"value":2}}]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithBatchInterval(time.Hour, 2))
+
+	var wg sync.WaitGroup
+	results := make([]struct {
+		Value int `json:"value"`
+	}, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := client.Run(context.Background(), NewRequest(`query { a }`), &results[i])
+			assert.NoError(t, err)
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected max-count flush to send before the interval elapsed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, int32(1), calls)
+}
+
+// TestWithBatchIntervalRejectsInterceptors verifies that Run reports an
+// error, rather than silently dropping WithBearerToken/WithRetry/
+// WithInterceptors, when the Client was also built with
+// WithBatchInterval: batched requests share one HTTP call, so there is
+// no per-request RoundTrip left for those interceptors to wrap.
+func TestWithBatchIntervalRejectsInterceptors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("server should not be called")
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL,
+		WithBatchInterval(time.Hour, 0),
+		WithBearerToken(func(ctx context.Context) (string, time.Time, error) {
+			return "tok", time.Time{}, nil
+		}),
+	)
+
+	err := client.Run(context.Background(), NewRequest(`query { a }`), nil)
+	assert.Error(t, err)
+}
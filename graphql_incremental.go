@@ -0,0 +1,348 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// acceptIncremental is the Accept header used to negotiate @defer/@stream
+// execution, per the incremental delivery spec:
+// https://github.com/graphql/graphql-over-http/blob/main/rfcs/IncrementalDelivery.md
+const acceptIncremental = "multipart/mixed; deferSpec=20220824, application/json"
+
+// IncrementalPayload is a single patch delivered while streaming a
+// response that uses @defer or @stream.
+type IncrementalPayload struct {
+	// Path is the location within the response that this patch applies
+	// to, as a sequence of field names (string) and list indices
+	// (float64), matching the GraphQL "path" encoding.
+	Path []interface{}
+	// Data is the deferred data for this path (set for @defer).
+	Data json.RawMessage
+	// Items are additional list items for this path (set for @stream).
+	Items []json.RawMessage
+	// Errors holds any errors reported alongside this patch.
+	Errors Errors
+	// HasNext reports whether more patches will follow.
+	HasNext bool
+}
+
+type incrementalEnvelope struct {
+	HasNext     bool        `json:"hasNext"`
+	Data        interface{} `json:"data,omitempty"`
+	Errors      Errors      `json:"errors,omitempty"`
+	Incremental []struct {
+		Path   []interface{}     `json:"path"`
+		Data   json.RawMessage   `json:"data,omitempty"`
+		Items  []json.RawMessage `json:"items,omitempty"`
+		Errors Errors            `json:"errors,omitempty"`
+	} `json:"incremental,omitempty"`
+}
+
+// RunIncremental executes a query that may use @defer/@stream and merges
+// each incremental payload into resp as it arrives, calling onPatch for
+// every patch (including the initial response). It negotiates
+// multipart/mixed and falls back to an ordinary single response if the
+// server does not support incremental delivery.
+//
+// Like Run, RunIncremental is wrapped by Client.Use/WithMiddleware and
+// has its initial request routed through WithInterceptors/
+// WithBearerToken/WithRetry, so auth and other per-request behavior
+// configured on the Client apply here too. WithRetry only covers that
+// initial request's status code, though: once the multipart/mixed body
+// has started streaming there is nothing left to safely replay, so a
+// failure mid-stream is returned as-is rather than retried.
+func (c *Client) RunIncremental(ctx context.Context, req *Request, resp interface{}, onPatch func(IncrementalPayload) error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	c.middlewareMu.RLock()
+	mw := c.middleware
+	c.middlewareMu.RUnlock()
+
+	handler := func(ctx context.Context, req *Request, resp interface{}) error {
+		return c.runIncremental(ctx, req, resp, onPatch)
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
+	}
+	return handler(ctx, req, resp)
+}
+
+// buildIncrementalRequest builds the initial @defer/@stream request for
+// req, negotiating multipart/mixed via acceptIncremental.
+func (c *Client) buildIncrementalRequest(ctx context.Context, req *Request) (*http.Request, error) {
+	var requestBody bytes.Buffer
+	requestBodyObj := struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}{
+		Query:     req.q,
+		Variables: req.vars,
+	}
+	if err := json.NewEncoder(&requestBody).Encode(requestBodyObj); err != nil {
+		return nil, errors.Wrap(err, "encode body")
+	}
+
+	r, err := http.NewRequest(http.MethodPost, c.endpoint, &requestBody)
+	if err != nil {
+		return nil, err
+	}
+	r.Close = c.closeReq
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("Accept", acceptIncremental)
+	for key, values := range req.Header {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	return r.WithContext(ctx), nil
+}
+
+// runIncremental is RunIncremental's inner Handler: it performs the
+// initial request, routed through the interceptor chain like the other
+// transports, then streams the multipart/mixed response if the server
+// negotiated one.
+func (c *Client) runIncremental(ctx context.Context, req *Request, resp interface{}, onPatch func(IncrementalPayload) error) error {
+	var res *http.Response
+	var lastStatus int
+	var lastRetryAfter time.Duration
+	_, err := c.chainInterceptors(func(ctx context.Context, req *Request) (*Response, error) {
+		r, err := c.buildIncrementalRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		out, err := c.httpClient.Do(r)
+		if err != nil {
+			return nil, err
+		}
+		lastStatus = out.StatusCode
+		lastRetryAfter = parseRetryAfter(out.Header)
+		if isRetryableStatus(lastStatus) {
+			// Nothing has been read from the body yet, so it's safe to let
+			// WithRetry retry this the same way it would any other
+			// transport's retryable response.
+			out.Body.Close()
+			return &Response{StatusCode: lastStatus, RetryAfter: lastRetryAfter, retryable: true}, nil
+		}
+		res = out
+		return &Response{StatusCode: lastStatus, RetryAfter: lastRetryAfter}, nil
+	})(ctx, req)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		// The attempt came back with a retryable status and no interceptor
+		// turned that into its own error (WithRetry giving up returns a
+		// MaxAttemptsExceededError instead); report the last status like
+		// the other transports do.
+		return &httpStatusError{StatusCode: lastStatus, RetryAfter: lastRetryAfter}
+	}
+	defer res.Body.Close()
+
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		return errors.Wrap(err, "parsing content type")
+	}
+	if mediaType != "multipart/mixed" {
+		// server does not support incremental delivery: treat the whole
+		// body as a single, non-deferred response.
+		gr := &graphResponse{Data: resp}
+		if err := json.NewDecoder(res.Body).Decode(gr); err != nil {
+			return errors.Wrap(err, "decoding response")
+		}
+		if len(gr.Errors) > 0 {
+			return gr.Errors
+		}
+		if onPatch != nil {
+			data, _ := json.Marshal(resp)
+			return onPatch(IncrementalPayload{Data: data, HasNext: false})
+		}
+		return nil
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return errors.New("multipart/mixed response missing boundary")
+	}
+	mr := multipart.NewReader(res.Body, boundary)
+
+	var merged interface{}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading multipart part")
+		}
+
+		body, err := io.ReadAll(part)
+		if err != nil {
+			return errors.Wrap(err, "reading part body")
+		}
+		if len(bytes.TrimSpace(body)) == 0 {
+			continue
+		}
+
+		var env incrementalEnvelope
+		if err := json.Unmarshal(body, &env); err != nil {
+			return errors.Wrap(err, "decoding incremental payload")
+		}
+
+		if env.Data != nil {
+			merged = env.Data
+			if onPatch != nil {
+				data, _ := json.Marshal(env.Data)
+				if err := onPatch(IncrementalPayload{Data: data, Errors: env.Errors, HasNext: env.HasNext}); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, inc := range env.Incremental {
+			if err := mergeAtPath(&merged, inc.Path, inc.Data, inc.Items); err != nil {
+				return errors.Wrap(err, "merging incremental payload")
+			}
+			if onPatch != nil {
+				if err := onPatch(IncrementalPayload{
+					Path:    inc.Path,
+					Data:    inc.Data,
+					Items:   inc.Items,
+					Errors:  inc.Errors,
+					HasNext: env.HasNext,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(env.Errors) > 0 {
+			return env.Errors
+		}
+		if !env.HasNext {
+			break
+		}
+	}
+
+	if resp == nil || merged == nil {
+		return nil
+	}
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return errors.Wrap(err, "marshaling merged response")
+	}
+	if err := json.Unmarshal(mergedJSON, resp); err != nil {
+		return errors.Wrap(err, "decoding merged response")
+	}
+	return nil
+}
+
+// mergeAtPath applies data (for @defer) or appends items (for @stream) at
+// the location in root described by path, a mix of object field names
+// (string) and list indices (float64), growing objects/lists as needed.
+func mergeAtPath(root *interface{}, path []interface{}, data json.RawMessage, items []json.RawMessage) error {
+	updated, err := applyPatch(*root, path, data, items)
+	if err != nil {
+		return err
+	}
+	*root = updated
+	return nil
+}
+
+func applyPatch(node interface{}, path []interface{}, data json.RawMessage, items []json.RawMessage) (interface{}, error) {
+	if len(path) == 0 {
+		return node, errors.New("incremental payload had an empty path")
+	}
+
+	switch seg := path[0].(type) {
+	case string:
+		m, _ := node.(map[string]interface{})
+		if m == nil {
+			m = map[string]interface{}{}
+		}
+		if len(path) == 1 {
+			if err := applyLeaf(m, seg, data, items); err != nil {
+				return nil, err
+			}
+			return m, nil
+		}
+		child, err := applyPatch(m[seg], path[1:], data, items)
+		if err != nil {
+			return nil, err
+		}
+		m[seg] = child
+		return m, nil
+	case float64:
+		idx := int(seg)
+		s, _ := node.([]interface{})
+		for len(s) <= idx {
+			s = append(s, nil)
+		}
+		if len(path) == 1 {
+			if err := applyLeaf(s, idx, data, items); err != nil {
+				return nil, err
+			}
+			return s, nil
+		}
+		child, err := applyPatch(s[idx], path[1:], data, items)
+		if err != nil {
+			return nil, err
+		}
+		s[idx] = child
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unexpected path element %v (%T)", seg, seg)
+	}
+}
+
+// applyLeaf is the generic "set or append" step shared by the
+// map[string]interface{} and []interface{} cases of applyPatch.
+func applyLeaf(container interface{}, key interface{}, data json.RawMessage, items []json.RawMessage) error {
+	get := func() interface{} {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			return c[key.(string)]
+		case []interface{}:
+			return c[key.(int)]
+		}
+		return nil
+	}
+	set := func(v interface{}) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[key.(string)] = v
+		case []interface{}:
+			c[key.(int)] = v
+		}
+	}
+
+	if data != nil {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		set(v)
+	}
+	for _, item := range items {
+		var v interface{}
+		if err := json.Unmarshal(item, &v); err != nil {
+			return err
+		}
+		list, _ := get().([]interface{})
+		set(append(list, v))
+	}
+	return nil
+}
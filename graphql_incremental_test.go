@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunIncrementalDeferred(t *testing.T) {
+	const boundary = "gqlmultipart"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%s", boundary))
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, "--%s\r\n", boundary)
+		io.WriteString(w, "Content-Type: application/json\r\n\r\n")
+		io.WriteString(w, `{"data":{"person":{"name":"matryer"}},"hasNext":true}`)
+		fmt.Fprintf(w, "\r\n--%s\r\n", boundary)
+		io.WriteString(w, "Content-Type: application/json\r\n\r\n")
+		io.WriteString(w, `{"hasNext":false,"incremental":[{"path":["person","age"],"data":42}]}`)
+		fmt.Fprintf(w, "\r\n--%s--\r\n", boundary)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewClient(srv.URL)
+
+	var resp struct {
+		Person struct {
+			Name string
+			Age  int
+		}
+	}
+
+	var patches int
+	err := client.RunIncremental(ctx, &Request{q: "query { person { name ... @defer { age } } }"}, &resp, func(p IncrementalPayload) error {
+		patches++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, patches)
+	assert.Equal(t, "matryer", resp.Person.Name)
+	assert.Equal(t, 42, resp.Person.Age)
+}
+
+// TestRunIncrementalAppliesInterceptorsAndMiddleware verifies that
+// WithBearerToken, WithRetry and Client.Use, all previously bypassed by
+// RunIncremental's direct httpClient.Do call, now wrap its initial
+// request like they do for Run.
+func TestRunIncrementalAppliesInterceptorsAndMiddleware(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		if calls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"value":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL,
+		WithBearerToken(func(ctx context.Context) (string, time.Time, error) {
+			return "tok", time.Time{}, nil
+		}),
+		WithRetry(3, time.Millisecond),
+	)
+
+	var middlewareRan bool
+	client.Use(func(next Handler) Handler {
+		return func(ctx context.Context, req *Request, resp interface{}) error {
+			middlewareRan = true
+			return next(ctx, req, resp)
+		}
+	})
+
+	var resp struct{ Value string }
+	err := client.RunIncremental(ctx, NewRequest("query {}"), &resp, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp.Value)
+	assert.Equal(t, 2, calls) // one 503, then a retry that succeeds
+	assert.True(t, middlewareRan)
+}
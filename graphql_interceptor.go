@@ -0,0 +1,189 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Response is the parsed result of a single GraphQL request, before its
+// Data is decoded into the caller's response object. Interceptors
+// operate on Response so they can inspect or transform a result without
+// knowing the caller's target type.
+type Response struct {
+	Data   json.RawMessage
+	Errors Errors
+
+	// StatusCode is the HTTP status of the response that produced this
+	// Response, and RetryAfter is the duration parsed from a Retry-After
+	// header, if the server sent one. Both are populated by doHTTP so a
+	// RequestInterceptor such as the one WithRetry installs can classify
+	// the outcome without re-parsing the raw response.
+	StatusCode int
+	RetryAfter time.Duration
+
+	// retryable reports whether doHTTP judged this response transient:
+	// a retryable HTTP status, or Errors.IsRetryable(). It is consulted
+	// by WithRetry alongside StatusCode.
+	retryable bool
+}
+
+// RoundTrip performs a single GraphQL request and returns its parsed
+// response. It is the type of both the terminal, HTTP-performing step of
+// a request and of each RequestInterceptor's next parameter.
+type RoundTrip func(ctx context.Context, req *Request) (*Response, error)
+
+// RequestInterceptor wraps a RoundTrip, analogous to func(http.Handler)
+// http.Handler: it can inspect or mutate req before calling next, skip
+// calling next at all to short-circuit the request, call next more than
+// once to retry, or transform the *Response next returns before handing
+// it back up the chain.
+//
+// Interceptors see the parsed *Request, including its variables, files
+// and headers, which makes them a good place to attach auth, logging,
+// tracing spans, request signing or rate limiting without subclassing or
+// replacing the underlying http.Client. See WithInterceptors.
+type RequestInterceptor func(ctx context.Context, req *Request, next RoundTrip) (*Response, error)
+
+// WithInterceptors appends interceptors to the chain that wraps the
+// JSON and multipart transports, outermost first: the first interceptor
+// passed is the first to see the request and the last to see the
+// response.
+func WithInterceptors(interceptors ...RequestInterceptor) ClientOption {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// chainInterceptors wraps rt with c.interceptors, outermost first.
+func (c *Client) chainInterceptors(rt RoundTrip) RoundTrip {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], rt
+		rt = func(ctx context.Context, req *Request) (*Response, error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	return rt
+}
+
+// decodeResponse unmarshals r.Data into resp, if both are present, then
+// reports r.Errors, if any, as the request's error.
+func decodeResponse(r *Response, resp interface{}) error {
+	if resp != nil && len(r.Data) > 0 {
+		if err := json.Unmarshal(r.Data, resp); err != nil {
+			return errors.Wrap(err, "decoding response")
+		}
+	}
+	if len(r.Errors) > 0 {
+		return r.Errors
+	}
+	return nil
+}
+
+// envelope is the wire shape of a GraphQL response body. Data is kept as
+// raw JSON so it can be handed to an interceptor before being
+// unmarshalled into the caller's response object.
+type envelope struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+// doRawHTTP performs r against the server, applying the Client's
+// closeReq setting and request/response logging, and returns the raw
+// response together with its body. It is shared by doHTTP, which parses
+// a single GraphQL envelope, and RunBatch, which parses an array of
+// them.
+func (c *Client) doRawHTTP(ctx context.Context, r *http.Request) (*http.Response, []byte, error) {
+	r.Close = c.closeReq
+	r = r.WithContext(ctx)
+	res, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, res.Body); err != nil {
+		return nil, nil, errors.Wrap(err, "reading body")
+	}
+	c.logf("<< %s", buf.String())
+	return res, buf.Bytes(), nil
+}
+
+// doHTTP performs r against the server and parses the GraphQL response
+// envelope, independently of how the request body was built. It is
+// shared by the JSON and multipart transports.
+func (c *Client) doHTTP(ctx context.Context, r *http.Request) (*Response, error) {
+	res, body, err := c.doRawHTTP(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return parseEnvelope(res, body)
+}
+
+// parseEnvelope parses a GraphQL response envelope out of a raw HTTP
+// response, classifying it the same way regardless of which transport
+// produced it, so a RequestInterceptor such as the one WithRetry
+// installs sees a consistent notion of "retryable" whether it's wrapping
+// the JSON, multipart or persisted-query transport.
+func parseEnvelope(res *http.Response, body []byte) (*Response, error) {
+	retryAfter := parseRetryAfter(res.Header)
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, &httpStatusError{StatusCode: res.StatusCode, RetryAfter: retryAfter}
+		}
+		return nil, errors.Wrap(err, "decoding response")
+	}
+	retryable := isRetryableStatus(res.StatusCode) || env.Errors.IsRetryable()
+	return &Response{
+		Data:       env.Data,
+		Errors:     env.Errors,
+		StatusCode: res.StatusCode,
+		RetryAfter: retryAfter,
+		retryable:  retryable,
+	}, nil
+}
+
+// httpStatusError reports a non-2xx response whose body could not be
+// parsed as a GraphQL envelope. Its Error method preserves the message
+// format doHTTP has always returned here, so callers matching on the
+// string are unaffected; StatusCode and RetryAfter let WithRetry
+// classify it without re-parsing that message.
+type httpStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("graphql: server returned a non-200 status code: %v", e.StatusCode)
+}
+
+// isRetryableStatus reports whether code is a transient HTTP failure
+// worth retrying: 429 (Too Many Requests) or any 5xx.
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code < 600)
+}
+
+// parseRetryAfter parses a Retry-After header, in either the
+// delay-seconds or HTTP-date form, into a duration from now. It returns
+// 0 if the header is absent or unparseable.
+func parseRetryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithInterceptorsOrdering(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) RequestInterceptor {
+		return func(ctx context.Context, req *Request, next RoundTrip) (*Response, error) {
+			calls = append(calls, name+":before")
+			resp, err := next(ctx, req)
+			calls = append(calls, name+":after")
+			return resp, err
+		}
+	}
+
+	client := NewClient(srv.URL, WithInterceptors(record("outer"), record("inner")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.Equal(t, []string{"outer:before", "inner:before", "inner:after", "outer:after"}, calls)
+}
+
+func TestWithInterceptorsShortCircuit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("server should not be called")
+	}))
+	defer srv.Close()
+
+	cached := &Response{Data: []byte(`{"value":"cached"}`)}
+	fromCache := func(ctx context.Context, req *Request, next RoundTrip) (*Response, error) {
+		return cached, nil
+	}
+	client := NewClient(srv.URL, WithInterceptors(fromCache))
+
+	var resp struct {
+		Value string `json:"value"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp))
+	assert.Equal(t, "cached", resp.Value)
+}
+
+func TestWithInterceptorsMutateRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "replay", r.Header.Get("X-Attempt"))
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	tagRetry := func(ctx context.Context, req *Request, next RoundTrip) (*Response, error) {
+		req.Header.Set("X-Attempt", "replay")
+		return next(ctx, req)
+	}
+	client := NewClient(srv.URL, WithInterceptors(tagRetry))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+}
@@ -2,6 +2,7 @@ package graphql
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
@@ -90,6 +91,41 @@ func TestDoJSONBadRequestErr(t *testing.T) {
 	assert.Equal(t, "graphql: miscellaneous message as to why the the request was bad", err.Error())
 }
 
+func TestDoJSONMultiError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{
+			"errors": [
+				{
+					"message": "field not found",
+					"path": ["something"],
+					"locations": [{"line": 2, "column": 3}],
+					"extensions": {"code": "NOT_FOUND"}
+				},
+				{
+					"message": "second error"
+				}
+			]
+		}`)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	client := NewClient(srv.URL)
+
+	var responseData map[string]interface{}
+	err := client.Run(ctx, &Request{q: "query {}"}, &responseData)
+	assert.Equal(t, "graphql: field not found", err.Error())
+
+	var gerrs Errors
+	assert.True(t, errors.As(err, &gerrs))
+	assert.Len(t, gerrs, 2)
+	assert.Equal(t, []interface{}{"something"}, gerrs[0].Path)
+	assert.Equal(t, 2, gerrs[0].Locations[0].Line)
+	assert.Equal(t, "NOT_FOUND", gerrs[0].Extensions["code"])
+	assert.Equal(t, "second error", gerrs[1].Message)
+}
+
 func TestQueryJSON(t *testing.T) {
 	var calls int
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
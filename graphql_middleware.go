@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"context"
+	"regexp"
+)
+
+// Handler executes a single GraphQL request and decodes the result into
+// resp, exactly like Client.Run. It is the GraphQL-semantic analogue of
+// http.RoundTripper.
+type Handler func(ctx context.Context, req *Request, resp interface{}) error
+
+// Middleware wraps a Handler to add cross-cutting behaviour around
+// Client.Run, such as tracing, auth-token refresh, retry with backoff, or
+// request/response logging, without forking the client.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middleware to the chain that wraps every
+// Client.Run call. Middleware run in the order given, outermost first, so
+//  NewClient(endpoint, WithMiddleware(tracing, retry))
+// calls tracing, then retry, then the underlying transport.
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(client *Client) {
+		client.middleware = append(client.middleware, mw...)
+	}
+}
+
+// operationRe matches the leading "query"/"mutation"/"subscription"
+// keyword of a GraphQL document, plus its optional operation name.
+// Shorthand query syntax (e.g. "{ field }") matches nothing.
+var operationRe = regexp.MustCompile(`(?m)^\s*(query|mutation|subscription)\b\s*([A-Za-z_][A-Za-z0-9_]*)?`)
+
+// OperationNameAndType parses req's query document and returns its
+// operation name (empty for anonymous operations, including shorthand
+// queries) and its type: "query", "mutation" or "subscription". Shorthand
+// queries are reported as type "query". It's intended for middleware that
+// wants to tag traces, metrics or logs with the operation being run.
+func (req *Request) OperationNameAndType() (name string, opType string) {
+	m := operationRe.FindStringSubmatch(req.q)
+	if m == nil {
+		return "", "query"
+	}
+	return m[2], m[1]
+}
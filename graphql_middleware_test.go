@@ -0,0 +1,102 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMiddlewareOrdering(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request, resp interface{}) error {
+				calls = append(calls, name+":before")
+				err := next(ctx, req, resp)
+				calls = append(calls, name+":after")
+				return err
+			}
+		}
+	}
+
+	client := NewClient(srv.URL, WithMiddleware(record("outer"), record("inner")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.Equal(t, []string{"outer:before", "inner:before", "inner:after", "outer:after"}, calls)
+}
+
+func TestClientUseAppendsMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request, resp interface{}) error {
+				calls = append(calls, name)
+				return next(ctx, req, resp)
+			}
+		}
+	}
+
+	client := NewClient(srv.URL, WithMiddleware(record("ctor")))
+	client.Use(record("runtime"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), nil))
+	assert.Equal(t, []string{"ctor", "runtime"}, calls)
+}
+
+func TestWithMiddlewareShortCircuit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("server should not be called")
+	}))
+	defer srv.Close()
+
+	denyAll := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request, resp interface{}) error {
+			return errors.New("denied")
+		}
+	}
+	client := NewClient(srv.URL, WithMiddleware(denyAll))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	assert.EqualError(t, err, "denied")
+}
+
+func TestOperationNameAndType(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantName   string
+		wantOpType string
+	}{
+		{"query {}", "", "query"},
+		{"{ field }", "", "query"},
+		{"query GetUser { user { id } }", "GetUser", "query"},
+		{"mutation CreateUser($name: String!) { createUser(name: $name) { id } }", "CreateUser", "mutation"},
+		{"subscription OnMessage { message { id } }", "OnMessage", "subscription"},
+	}
+	for _, tt := range tests {
+		req := NewRequest(tt.query)
+		name, opType := req.OperationNameAndType()
+		assert.Equal(t, tt.wantName, name, tt.query)
+		assert.Equal(t, tt.wantOpType, opType, tt.query)
+	}
+}
@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMultipartSpecCompliance checks the request against the GraphQL
+// multipart request spec: an "operations" field with null placeholders,
+// a "map" field pointing file parts back at their variable, and numbered
+// file parts with a filename and content type.
+func TestMultipartSpecCompliance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/form-data", mediaType)
+
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		form, err := reader.ReadForm(10 << 20)
+		assert.NoError(t, err)
+
+		var operations struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(form.Value["operations"][0]), &operations))
+		assert.Nil(t, operations.Variables["avatar"])
+		files, _ := operations.Variables["files"].([]interface{})
+		assert.Equal(t, []interface{}{nil, nil}, files)
+
+		var fileMap map[string][]string
+		assert.NoError(t, json.Unmarshal([]byte(form.Value["map"][0]), &fileMap))
+		assert.Equal(t, []string{"variables.avatar"}, fileMap["0"])
+		assert.Equal(t, []string{"variables.files.0"}, fileMap["1"])
+		assert.Equal(t, []string{"variables.files.1"}, fileMap["2"])
+
+		for idx, want := range map[string]string{"0": "a.png", "1": "b.png", "2": "c.png"} {
+			fhs := form.File[idx]
+			if assert.Len(t, fhs, 1, idx) {
+				assert.Equal(t, want, fhs[0].Filename)
+				assert.Equal(t, "image/png", fhs[0].Header.Get("Content-Type"))
+			}
+		}
+
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, UseMultipartForm())
+	req := NewRequest(`mutation ($avatar: Upload!, $files: [Upload!]!) { uploadAvatar(avatar: $avatar, files: $files) }`)
+	req.Var("files", []interface{}{nil, nil})
+	req.File("variables.avatar", "a.png", strings.NewReader("a"))
+	req.File("variables.files.0", "b.png", strings.NewReader("b"))
+	req.File("variables.files.1", "c.png", strings.NewReader("c"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, req, nil))
+}
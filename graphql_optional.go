@@ -0,0 +1,42 @@
+package graphql
+
+import "encoding/json"
+
+// Optional represents a GraphQL nullable value as a Value plus a Valid
+// flag, for callers that would rather not use a pointer. JSON null and a
+// field absent from the JSON both leave it unset, so it does not tell an
+// explicit null from a value that was never sent. It's mainly consumed by code generated
+// by cmd/graphqlgen when run with -nullability=optional; the generator's
+// default, -nullability=pointer, needs no runtime support at all.
+type Optional[T any] struct {
+	Value T
+	Valid bool
+}
+
+// Opt wraps v as a set Optional value.
+func Opt[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Valid: true}
+}
+
+// MarshalJSON encodes an unset Optional as JSON null.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// UnmarshalJSON decodes JSON null into an unset Optional, and anything
+// else into a set Optional holding the decoded value.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = Optional[T]{}
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Optional[T]{Value: v, Valid: true}
+	return nil
+}
@@ -0,0 +1,149 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxRetryBackoff caps the jittered exponential backoff WithRetry uses
+// between attempts, regardless of baseDelay or how many attempts have
+// already been made.
+const maxRetryBackoff = 30 * time.Second
+
+// MaxAttemptsExceededError is returned by a Client configured with
+// WithRetry once every attempt has been used up. It wraps the error
+// from the last attempt so callers can still inspect it, including via
+// errors.As for an Errors or an *httpStatusError.
+type MaxAttemptsExceededError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *MaxAttemptsExceededError) Error() string {
+	return fmt.Sprintf("graphql: giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *MaxAttemptsExceededError) Unwrap() error {
+	return e.Err
+}
+
+// WithRetry makes the Client retry a request with jittered exponential
+// backoff when the transport reports a retryable HTTP status (429 or
+// 5xx) or the response body carries a GraphQL error that
+// Errors.IsRetryable considers transient (ErrCapacityExceeded,
+// ErrServiceUnavailable, ErrServiceFailure, ErrInternal). Backoff
+// starts at baseDelay and doubles on each attempt up to
+// maxRetryBackoff, honoring a Retry-After response header when the
+// server sends one instead. maxAttempts counts the first try, so
+// WithRetry(3, time.Second) makes up to two retries.
+//
+// It is implemented as a RequestInterceptor, so it composes with
+// WithInterceptors and the rest of the interceptor chain: place it last
+// among your options if you want it closest to the wire, retrying only
+// the HTTP round trip rather than re-running outer interceptors like
+// WithBearerToken on every attempt.
+func WithRetry(maxAttempts int, baseDelay time.Duration) ClientOption {
+	return func(client *Client) {
+		r := &retrier{maxAttempts: maxAttempts, baseDelay: baseDelay}
+		client.interceptors = append(client.interceptors, r.intercept)
+	}
+}
+
+// retrier holds the configuration for a single WithRetry installation.
+type retrier struct {
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+func (r *retrier) intercept(ctx context.Context, req *Request, next RoundTrip) (*Response, error) {
+	var lastErr error
+	backoff := r.baseDelay
+	for attempt := 0; attempt < r.maxAttempts; attempt++ {
+		if attempt > 0 {
+			wait := jitter(backoff)
+			if retryAfter := retryAfterOf(lastErr); retryAfter > 0 {
+				wait = retryAfter
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			backoff *= 2
+			if backoff > maxRetryBackoff {
+				backoff = maxRetryBackoff
+			}
+		}
+
+		if err := rewindRequestFiles(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := next(ctx, req)
+		if err == nil && !resp.retryable {
+			return resp, nil
+		}
+		if err != nil && !isRetryableErr(err) {
+			return nil, err
+		}
+		if err == nil {
+			if len(resp.Errors) > 0 {
+				err = resp.Errors
+			} else {
+				err = &httpStatusError{StatusCode: resp.StatusCode, RetryAfter: resp.RetryAfter}
+			}
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, &MaxAttemptsExceededError{Attempts: r.maxAttempts, Err: lastErr}
+}
+
+// isRetryableErr reports whether err, returned by a RoundTrip, is worth
+// retrying: currently only an *httpStatusError with a retryable status.
+func isRetryableErr(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatus(statusErr.StatusCode)
+	}
+	return false
+}
+
+// retryAfterOf extracts the Retry-After duration carried by err, if any.
+func retryAfterOf(err error) time.Duration {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
+
+// rewindRequestFiles prepares req's file attachments, if any, to be
+// read again by a retry attempt. A reader that implements io.Seeker is
+// seeked back to its start; anything else is read into memory once and
+// replaced with a *bytes.Reader, so later attempts replay the same
+// bytes without requiring the caller to pass a seekable reader.
+func rewindRequestFiles(req *Request) error {
+	for i := range req.files {
+		f := &req.files[i]
+		if seeker, ok := f.R.(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("graphql: rewind file %q for retry: %w", f.Name, err)
+			}
+			continue
+		}
+		buf, err := io.ReadAll(f.R)
+		if err != nil {
+			return fmt.Errorf("graphql: buffer file %q for retry: %w", f.Name, err)
+		}
+		f.R = bytes.NewReader(buf)
+	}
+	return nil
+}
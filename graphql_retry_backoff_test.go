@@ -0,0 +1,135 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRetrySucceedsAfterRetryableStatus(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"value":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithRetry(5, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var resp struct{ Value string }
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp))
+	assert.Equal(t, "ok", resp.Value)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetryHonorsRetryAfter(t *testing.T) {
+	var calls int
+	var gotDelay time.Duration
+	var last time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		now := time.Now()
+		if !last.IsZero() {
+			gotDelay = now.Sub(last)
+		}
+		last = now
+		if calls < 2 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"value":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithRetry(3, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var resp struct{ Value string }
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp))
+	assert.GreaterOrEqual(t, gotDelay, 900*time.Millisecond, "should have waited out the Retry-After header")
+}
+
+func TestWithRetryClassifiesLegacyErrorNames(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 2 {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"down for maintenance","name":"service_unavailable"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"value":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithRetry(3, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var resp struct{ Value string }
+	assert.NoError(t, client.Run(ctx, NewRequest("query {}"), &resp))
+	assert.Equal(t, 2, calls)
+}
+
+func TestWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithRetry(3, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	assert.Error(t, err)
+	var maxErr *MaxAttemptsExceededError
+	assert.ErrorAs(t, err, &maxErr)
+	assert.Equal(t, 3, maxErr.Attempts)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetryDoesNotRetryNonRetryableError(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors":[{"message":"bad query"}]}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithRetry(3, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	assert.EqualError(t, err, "graphql: bad query")
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithRetryAbortsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithRetry(5, 200*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.Run(ctx, NewRequest("query {}"), nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeMultiMessageAndNextInto(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		var msg subscriptionMessage
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, gqp_init, msg.Type)
+		assert.NoError(t, conn.WriteJSON(subscriptionMessage{Type: gql_connection_ack}))
+
+		assert.NoError(t, conn.ReadJSON(&msg))
+		id := *msg.Id
+
+		for _, v := range []string{`{"data":1}`, `{"data":2}`, `{"data":3}`} {
+			payload := json.RawMessage(v)
+			assert.NoError(t, conn.WriteJSON(subscriptionMessage{Id: &id, Type: gqlt_next, Payload: &payload}))
+		}
+
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, subscriptionMessageType(gqlt_complete), msg.Type)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	sub, err := client.Subscribe(ctx, NewRequest(`subscription { cnt }`))
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	var got []struct{ Data int }
+	for i := 0; i < 3; i++ {
+		var v struct{ Data int }
+		assert.NoError(t, sub.NextInto(ctx, &v))
+		got = append(got, v)
+	}
+	assert.Equal(t, 1, got[0].Data)
+	assert.Equal(t, 2, got[1].Data)
+	assert.Equal(t, 3, got[2].Data)
+}
+
+func TestWithKeepAlivePingSendsPings(t *testing.T) {
+	pinged := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		var msg subscriptionMessage
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.NoError(t, conn.WriteJSON(subscriptionMessage{Type: gql_connection_ack}))
+
+		assert.NoError(t, conn.ReadJSON(&msg)) // subscribe
+
+		for {
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			switch msg.Type {
+			case gqlt_ping:
+				select {
+				case pinged <- struct{}{}:
+				default:
+				}
+			case gqlt_complete:
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithKeepAlivePing(20*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	sub, err := client.Subscribe(ctx, NewRequest(`subscription { cnt }`))
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	select {
+	case <-pinged:
+	case <-time.After(1 * time.Second):
+		t.Fatal("expected a client ping within the configured interval")
+	}
+}
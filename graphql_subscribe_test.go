@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// upgrader is shared by every subscription test server in this package.
+var upgrader = websocket.Upgrader{}
+
+func TestSubscribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		var msg subscriptionMessage
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, gqp_init, msg.Type)
+		var initPayload struct {
+			Token string `json:"token"`
+		}
+		assert.NoError(t, json.Unmarshal(*msg.Payload, &initPayload))
+		assert.Equal(t, "secret", initPayload.Token)
+
+		assert.NoError(t, conn.WriteJSON(subscriptionMessage{Type: gql_connection_ack}))
+
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, subscriptionMessageType(gqlt_subscribe), msg.Type)
+		id := *msg.Id
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		assert.NoError(t, json.Unmarshal(*msg.Payload, &body))
+		assert.Equal(t, "subscription ($q: String) { cnt }", body.Query)
+		assert.Equal(t, "foo", body.Variables["q"])
+
+		payload := json.RawMessage(`{"data":"bar"}`)
+		assert.NoError(t, conn.WriteJSON(subscriptionMessage{Id: &id, Type: gqlt_next, Payload: &payload}))
+
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, subscriptionMessageType(gqlt_complete), msg.Type)
+		assert.Equal(t, id, *msg.Id)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithConnectionInitPayload(map[string]string{"token": "secret"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req := NewRequest(`subscription ($q: String) { cnt }`)
+	req.Var("q", "foo")
+	sub, err := client.Subscribe(ctx, req)
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	payload, err := sub.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":"bar"}`, string(payload))
+}
+
+func TestSubscribeServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		var msg subscriptionMessage
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, gqp_init, msg.Type)
+		assert.NoError(t, conn.WriteJSON(subscriptionMessage{Type: gql_connection_ack}))
+
+		assert.NoError(t, conn.ReadJSON(&msg))
+		id := *msg.Id
+
+		errPayload := json.RawMessage(`[{"message":"boom"}]`)
+		assert.NoError(t, conn.WriteJSON(subscriptionMessage{Id: &id, Type: gql_error, Payload: &errPayload}))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	sub, err := client.Subscribe(ctx, NewRequest(`subscription { cnt }`))
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	_, err = sub.Next(ctx)
+	assert.EqualError(t, err, "graphql: boom")
+}
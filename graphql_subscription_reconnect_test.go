@@ -0,0 +1,125 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// TestSubReconnect verifies that a dropped subscription connection is
+// transparently reconnected and resubscribed, and that the caller's
+// Subscription channel keeps delivering data rather than being closed.
+func TestSubReconnect(t *testing.T) {
+	is := is.New(t)
+
+	var connects int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		is.NoErr(err)
+		connects++
+		thisConnect := connects
+		defer c.Close()
+
+		var pl subscriptionMessage
+		pl.Type = gql_connection_ack
+		is.NoErr(c.WriteJSON(pl))
+
+		is.NoErr(c.ReadJSON(&pl))
+		is.Equal(string(pl.Type), gql_start)
+		id := *pl.Id
+
+		if thisConnect == 1 {
+			// simulate a dropped connection before any data is sent.
+			return
+		}
+
+		pl_pl := json.RawMessage(`{"data": "bar"}`)
+		pl.Id = &id
+		pl.Payload = &pl_pl
+		pl.Type = gql_data
+		is.NoErr(c.WriteJSON(pl))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := NewClient(srv.URL, WithSubscriptionReconnect(10*time.Millisecond, 20*time.Millisecond))
+
+	cl, err := client.SubscriptionClient(ctx, http.Header{})
+	is.NoErr(err)
+	defer cl.Close()
+
+	vars := make(map[string]interface{})
+	vars["q"] = "foo"
+	sub, err := cl.Subscribe(&Request{q: `subscription ($q: String) { cnt }`, vars: vars})
+	is.NoErr(err)
+
+	select {
+	case res := <-sub:
+		is.Equal(string(*res.Data), `{"data":"bar"}`)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for data after reconnect")
+	}
+
+	is.True(connects >= 2) // client reconnected after the first drop
+}
+
+// TestSubscriptionClientCloseReturns verifies that Close returns promptly
+// even while subWork is blocked in conn.ReadJSON on an otherwise-idle
+// connection, rather than deadlocking waiting for a read that can only be
+// unblocked by the close itself.
+func TestSubscriptionClientCloseReturns(t *testing.T) {
+	is := is.New(t)
+
+	connected := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		is.NoErr(err)
+		defer c.Close()
+
+		var pl subscriptionMessage
+		pl.Type = gql_connection_ack
+		is.NoErr(c.WriteJSON(pl))
+
+		is.NoErr(c.ReadJSON(&pl))
+		close(connected)
+
+		// go quiet: no further messages, simulating an idle connection.
+		select {}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := NewClient(srv.URL)
+
+	cl, err := client.SubscriptionClient(ctx, http.Header{})
+	is.NoErr(err)
+
+	vars := make(map[string]interface{})
+	_, err = cl.Subscribe(&Request{q: `subscription { cnt }`, vars: vars})
+	is.NoErr(err)
+
+	select {
+	case <-connected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for server to receive subscribe")
+	}
+	// give subWork a moment to settle into its blocking ReadJSON.
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- cl.Close() }()
+
+	select {
+	case err := <-done:
+		is.NoErr(err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("Close deadlocked")
+	}
+}
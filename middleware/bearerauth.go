@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/machinebox/graphql"
+)
+
+// BearerAuth returns a Middleware that sets an "Authorization: Bearer
+// <token>" header on every request, calling tokenFn to get the token
+// each time. Unlike graphql.WithBearerToken, it does not cache the
+// token between requests; give tokenFn its own caching if fetching it
+// is expensive.
+func BearerAuth(tokenFn func(ctx context.Context) (string, error)) graphql.Middleware {
+	return func(next graphql.Handler) graphql.Handler {
+		return func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			token, err := tokenFn(ctx)
+			if err != nil {
+				return fmt.Errorf("middleware: fetch bearer token: %w", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			return next(ctx, req, resp)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Package middleware provides ready-made graphql.Middleware
+// implementations for cross-cutting concerns that don't belong in the
+// core client: request logging, distributed tracing, typed-error-aware
+// retry and bearer token injection. Install them with Client.Use or
+// graphql.WithMiddleware, in the order you want them to run:
+//
+//	client := graphql.NewClient(endpoint)
+//	client.Use(
+//		middleware.Logging(log.Printf),
+//		middleware.Retry(3, 200*time.Millisecond),
+//		middleware.BearerAuth(fetchToken),
+//	)
+package middleware
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/machinebox/graphql"
+)
+
+// Logging returns a Middleware that logs every request's operation name
+// and type, its duration, and its error, if any, via logf, in the same
+// style as Client.Log.
+func Logging(logf func(format string, args ...interface{})) graphql.Middleware {
+	return func(next graphql.Handler) graphql.Handler {
+		return func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			name, opType := req.OperationNameAndType()
+			start := time.Now()
+			err := next(ctx, req, resp)
+			logf("graphql: %s %s took %s, err=%v", opType, name, time.Since(start), err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/machinebox/graphql"
+	"github.com/machinebox/graphql/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogging(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	var logged string
+	client := graphql.NewClient(srv.URL)
+	client.Use(middleware.Logging(func(format string, args ...interface{}) {
+		logged = fmt.Sprintf(format, args...)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, graphql.NewRequest("query GetUser { user { id } }"), nil))
+	assert.Contains(t, logged, "query")
+	assert.Contains(t, logged, "GetUser")
+	assert.Contains(t, logged, "err=<nil>")
+}
+
+func TestRetryRetriesRetryableErrors(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"try again","name":"internal_error"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"value":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL)
+	client.Use(middleware.Retry(5, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var resp struct{ Value string }
+	assert.NoError(t, client.Run(ctx, graphql.NewRequest("query {}"), &resp))
+	assert.Equal(t, "ok", resp.Value)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryGivesUpOnNonRetryableError(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"errors":[{"message":"nope","name":"not_found"}]}`))
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL)
+	client.Use(middleware.Retry(5, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	err := client.Run(ctx, graphql.NewRequest("query {}"), nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBearerAuthSetsHeaderPerRequest(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL)
+	client.Use(middleware.BearerAuth(func(ctx context.Context) (string, error) {
+		return "tok", nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Run(ctx, graphql.NewRequest("query {}"), nil))
+	assert.Equal(t, "Bearer tok", gotAuth)
+}
+
+func TestOpenTelemetryPassesErrorThrough(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"boom"}]}`))
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL)
+	client.Use(middleware.OpenTelemetry())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	err := client.Run(ctx, graphql.NewRequest("query {}"), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/machinebox/graphql"
+)
+
+// maxRetryBackoff caps the backoff Retry uses between attempts,
+// regardless of baseDelay or how many attempts have already been made.
+const maxRetryBackoff = 30 * time.Second
+
+// Retry returns a Middleware that retries a request with jittered
+// exponential backoff, starting at baseDelay and doubling on each
+// attempt up to maxRetryBackoff, when it fails with a graphql.Errors
+// that graphql.Errors.IsRetryable considers transient. Unlike
+// graphql.WithRetry, which classifies the raw HTTP transport too, Retry
+// only sees the decoded error Client.Run returns, so it can't retry a
+// transport-level failure (a non-200 status, a connection error); use
+// graphql.WithRetry for that. It gives up and returns the last error
+// once maxAttempts is reached.
+func Retry(maxAttempts int, baseDelay time.Duration) graphql.Middleware {
+	return func(next graphql.Handler) graphql.Handler {
+		return func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			var err error
+			for attempt := 0; attempt < maxAttempts; attempt++ {
+				err = next(ctx, req, resp)
+				if err == nil {
+					return nil
+				}
+				var gerrs graphql.Errors
+				if !errors.As(err, &gerrs) || !gerrs.IsRetryable() {
+					return err
+				}
+				if attempt == maxAttempts-1 {
+					break
+				}
+				select {
+				case <-time.After(backoff(baseDelay, attempt)):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return err
+		}
+	}
+}
+
+// backoff computes a jittered exponential delay for the given attempt,
+// doubling baseDelay each time and capping at maxRetryBackoff.
+func backoff(baseDelay time.Duration, attempt int) time.Duration {
+	d := baseDelay << attempt
+	if d <= 0 || d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2+1)))
+}
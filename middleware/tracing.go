@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/machinebox/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name passed to otel.Tracer, so spans
+// this middleware produces are easy to find in a trace viewer. Span
+// names themselves are prefixed with "graphql.".
+const tracerName = "github.com/machinebox/graphql"
+
+// OpenTelemetry returns a Middleware that wraps each request in a span
+// from the global otel.Tracer, named after the GraphQL operation (e.g.
+// "graphql.query GetUser"), tagged with the operation type and name, and
+// marked as errored if Run returns one.
+func OpenTelemetry() graphql.Middleware {
+	tracer := otel.Tracer(tracerName)
+	return func(next graphql.Handler) graphql.Handler {
+		return func(ctx context.Context, req *graphql.Request, resp interface{}) error {
+			name, opType := req.OperationNameAndType()
+			spanName := "graphql." + opType
+			if name != "" {
+				spanName += " " + name
+			}
+			ctx, span := tracer.Start(ctx, spanName, trace.WithAttributes(
+				attribute.String("graphql.operation.type", opType),
+				attribute.String("graphql.operation.name", name),
+			))
+			defer span.End()
+
+			err := next(ctx, req, resp)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}